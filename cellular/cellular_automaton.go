@@ -6,93 +6,236 @@ package cellular
 
 import (
 	"math"
+	"math/bits"
 	"math/rand"
 )
 
 // CA is a cellular automaton
 type CA struct {
 	Rule                   uint8
-	State                  []uint64
+	Size                   int
+	State, Next            []uint64
 	Connections            []int
+	Couplings              []Coupling
 	On                     uint64
+	Alpha                  float64
 	Low, Complexity, Spike float64
 	Threshold              float64
 	Note                   uint8
+	Detector               SpikeDetector
+	Tracker                *Tracker
+	// Absolute and Relative are the absolute and relative refractory periods in steps
+	Absolute, Relative int
+	// Refractory and Recovery are the remaining steps of the refractory periods
+	Refractory, Recovery int
+	// Adaptation is added to Adapted on each spike, Adapted decays by AdaptationDecay each step
+	Adaptation, Adapted float64
 }
 
-// NewCA creates a new cellular automaton
+// NewCA creates a new cellular automaton with size cells
 func NewCA(rule uint8, size int, threshold float64, rnd *rand.Rand) CA {
-	state := make([]uint64, size)
+	chunks := (size + ChunkSize - 1) / ChunkSize
+	state := make([]uint64, chunks)
 	for j := range state {
 		state[j] = rnd.Uint64()
 	}
-	return CA{
+	ca := CA{
 		Rule:        rule,
+		Size:        size,
 		State:       state,
+		Next:        make([]uint64, chunks),
 		Connections: make([]int, 0, 8),
-		Low:         CASize / 2,
+		Couplings:   make([]Coupling, 0, 8),
+		Alpha:       Alpha,
+		Low:         float64(size) / 2,
 		Threshold:   threshold,
 	}
+	ca.Trim()
+	return ca
 }
 
 // AddConnection adds a connection to another cellular automaton
 func (ca *CA) AddConnection(n int) {
+	ca.AddCoupledConnection(n, nil)
+}
+
+// AddCoupledConnection adds a connection to another cellular automaton with a coupling,
+// a nil coupling uses the network coupling
+func (ca *CA) AddCoupledConnection(n int, coupling Coupling) {
 	ca.Connections = append(ca.Connections, n)
+	ca.Couplings = append(ca.Couplings, coupling)
+}
+
+// Coupling returns the coupling for the connection to n
+func (ca *CA) Coupling(n int) Coupling {
+	for i, c := range ca.Connections {
+		if c == n {
+			return ca.Couplings[i]
+		}
+	}
+	return nil
 }
 
-// Test checks if the cellular automaton is firing
+// Test checks if the cellular automaton is firing, the default without a detector
+// is the spike exceeding the effective threshold divided by SpikeFactor
 func (ca *CA) Test() bool {
-	return ca.Spike > ca.Threshold/SpikeFactor
+	if ca.Refractory > 0 {
+		return false
+	}
+	if ca.Detector != nil {
+		return ca.Detector.Test(ca)
+	}
+	return ca.Spike > ca.EffectiveThreshold()/SpikeFactor
+}
+
+// EffectiveThreshold is the threshold raised by adaptation and the relative refractory period
+func (ca *CA) EffectiveThreshold() float64 {
+	gain := 1 + ca.Adapted
+	if ca.Relative > 0 {
+		gain += float64(ca.Recovery) / float64(ca.Relative)
+	}
+	return gain * ca.Threshold
+}
+
+// Spiked starts the refractory periods and adapts after the cellular automaton fires
+func (ca *CA) Spiked() {
+	ca.Refractory, ca.Recovery = ca.Absolute, ca.Relative
+	ca.Adapted += ca.Adaptation
+	if refractor, ok := ca.Detector.(Refractor); ok {
+		refractor.Spiked()
+	}
+}
+
+// Rest counts down the refractory periods when the cellular automaton does not fire
+func (ca *CA) Rest() {
+	if ca.Refractory > 0 {
+		ca.Refractory--
+	} else if ca.Recovery > 0 {
+		ca.Recovery--
+	}
+	if refractor, ok := ca.Detector.(Refractor); ok {
+		refractor.Rest()
+	}
+}
+
+// Bit returns the value of cell i
+func (ca *CA) Bit(i int) uint64 {
+	return (ca.State[i>>6] >> uint(i&0x3F)) & 0x1
+}
+
+// Trim clears the unused bits past the end of the last chunk
+func (ca *CA) Trim() {
+	if tail := uint(ca.Size & 0x3F); tail != 0 {
+		ca.State[len(ca.State)-1] &= (1 << tail) - 1
+	}
 }
 
-// Step generates the next step of the cellular automaton
-func (ca *CA) Step(next []uint64) []uint64 {
-	rule, state, on := ca.Rule, ca.State, uint64(0)
-	length := len(state)
-	index, out := state[length-1]>>63, 0
-	for i, s := range state {
-		c := ChunkSize
+// Rule computes the next state of 64 cells given their left, center and right neighbors
+func Rule(rule uint8, left, center, right uint64) uint64 {
+	if rule == 110 {
+		return (center | right) &^ (left & center & right)
+	}
+	next := uint64(0)
+	for i := uint(0); i < 8; i++ {
+		if (rule>>i)&0x1 == 0 {
+			continue
+		}
+		term := ^uint64(0)
+		if i&0x4 == 0 {
+			term &^= left
+		} else {
+			term &= left
+		}
+		if i&0x2 == 0 {
+			term &^= center
+		} else {
+			term &= center
+		}
+		if i&0x1 == 0 {
+			term &^= right
+		} else {
+			term &= right
+		}
+		next |= term
+	}
+	return next
+}
+
+// Step generates the next step of the cellular automaton a word at a time
+func (ca *CA) Step() {
+	rule, size, state, next, on := ca.Rule, ca.Size, ca.State, ca.Next, 0
+	last := len(state) - 1
+	for i, center := range state {
+		var left, right uint64
 		if i == 0 {
-			index = ((index << 1) & 0x7) | (s & 0x1)
-			s >>= 1
-			c--
+			left = center<<1 | ca.Bit(size-1)
+		} else {
+			left = center<<1 | state[i-1]>>63
 		}
-		next[i] = 0
-		for c > 0 {
-			index = ((index << 1) & 0x7) | (s & 0x1)
-			s >>= 1
-			bit := uint64((rule >> index) & 0x1)
-			on += bit
-			next[out>>6] |= bit << uint(out&0x3F)
-			c--
-			out++
+		if i == last {
+			right = center>>1 | (state[0]&0x1)<<uint((size-1)&0x3F)
+		} else {
+			right = center>>1 | state[i+1]<<63
 		}
+		next[i] = Rule(rule, left, center, right)
+	}
+	if tail := uint(size & 0x3F); tail != 0 {
+		next[last] &= (1 << tail) - 1
 	}
-	index = ((index << 1) & 0x7) | (state[0] & 0x1)
-	bit := uint64((rule >> index) & 0x1)
-	on += bit
-	next[out>>6] |= bit << uint(out&0x3F)
-	ca.State, ca.On = next, on
+	for _, s := range next {
+		on += bits.OnesCount64(s)
+	}
+	ca.State, ca.Next = next, state
+	if ca.Tracker != nil {
+		ca.Tracker.Update(ca)
+	}
+	ca.update(uint64(on))
+}
 
-	low, complexity := ca.Low, ca.Complexity
-	low = low + Alpha*(float64(on)-low)
-	complexity = complexity + Alpha*(math.Abs(float64(on)-low)-complexity)
-	ca.Low, ca.Complexity, ca.Spike = low, complexity, math.Exp(-complexity)
+// StepBits generates the next step of the cellular automaton a bit at a time
+func (ca *CA) StepBits() {
+	rule, size, next, on := ca.Rule, ca.Size, ca.Next, uint64(0)
+	for i := range next {
+		next[i] = 0
+	}
+	index := ca.Bit(size-1)<<1 | ca.Bit(0)
+	for i := 0; i < size; i++ {
+		right := i + 1
+		if right == size {
+			right = 0
+		}
+		index = ((index << 1) & 0x7) | ca.Bit(right)
+		bit := uint64((rule >> index) & 0x1)
+		on += bit
+		next[i>>6] |= bit << uint(i&0x3F)
+	}
+	ca.State, ca.Next = next, ca.State
+	if ca.Tracker != nil {
+		ca.Tracker.Update(ca)
+	}
+	ca.update(on)
+}
 
-	return state
+// update updates the spike statistics with the number of on cells
+func (ca *CA) update(on uint64) {
+	low, complexity, alpha := ca.Low, ca.Complexity, ca.Alpha
+	low = low + alpha*(float64(on)-low)
+	complexity = complexity + alpha*(math.Abs(float64(on)-low)-complexity)
+	ca.On, ca.Low, ca.Complexity, ca.Spike = on, low, complexity, math.Exp(-complexity)
+	ca.Adapted *= AdaptationDecay
+	if ca.Detector != nil {
+		ca.Detector.Update(ca)
+	}
 }
 
 // String converts the cellular automaton to a string
 func (ca *CA) String() string {
 	state := ""
-	for _, s := range ca.State {
-		for i := 0; i < 64; i++ {
-			if s&0x1 == 0 {
-				state += "0"
-			} else {
-				state += "1"
-			}
-			s >>= 1
+	for i := 0; i < ca.Size; i++ {
+		if ca.Bit(i) == 0 {
+			state += "0"
+		} else {
+			state += "1"
 		}
 	}
 	return state
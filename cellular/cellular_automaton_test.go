@@ -0,0 +1,124 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCA_Step(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range [...]int{1, 2, 3, 63, 64, 65, 127, 128, 200, CASize} {
+		for rule := 0; rule < 256; rule++ {
+			a := NewCA(uint8(rule), size, SpikeThreshold, rnd)
+			b := a
+			b.State, b.Next = make([]uint64, len(a.State)), make([]uint64, len(a.Next))
+			copy(b.State, a.State)
+			for i := 0; i < 64; i++ {
+				a.Step()
+				b.StepBits()
+				if a.On != b.On || a.Complexity != b.Complexity {
+					t.Fatalf("rule %d size %d step %d: statistics differ", rule, size, i)
+				}
+				for j := range a.State {
+					if a.State[j] != b.State[j] {
+						t.Fatalf("rule %d size %d step %d: %s != %s", rule, size, i, a.String(), b.String())
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkCA_Step(b *testing.B) {
+	ca := NewCA(110, CASize, SpikeThreshold, rand.New(rand.NewSource(1)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ca.Step()
+	}
+}
+
+func BenchmarkCA_StepBits(b *testing.B) {
+	ca := NewCA(110, CASize, SpikeThreshold, rand.New(rand.NewSource(1)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ca.StepBits()
+	}
+}
+
+func BenchmarkCA_StepRule30(b *testing.B) {
+	ca := NewCA(30, CASize, SpikeThreshold, rand.New(rand.NewSource(1)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ca.Step()
+	}
+}
+
+func TestClassify(t *testing.T) {
+	vectors := [...]struct {
+		rule  uint8
+		class int
+	}{
+		{0, 1},
+		{4, 2},
+		{30, 3},
+		{90, 3},
+		{110, 4},
+	}
+	for _, v := range vectors {
+		c := Classify(v.rule, 401, 2048, 8, rand.New(rand.NewSource(1)))
+		if c.Class != v.class {
+			t.Errorf("rule %d is class %d not %d", v.rule, c.Class, v.class)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	ca := NewCA(110, 20*EtherWidth, SpikeThreshold, rand.New(rand.NewSource(1)))
+	for i := range ca.State {
+		ca.State[i] = 0
+	}
+	for i := 0; i < ca.Size; i++ {
+		if Ether[i%EtherWidth] == '1' {
+			ca.State[i>>6] |= 1 << uint(i&0x3F)
+		}
+	}
+	ca.Tracker = NewTracker(NewEtherFilter())
+	for i := 0; i < 70; i++ {
+		ca.Step()
+		if particles := len(ca.Tracker.Particles); particles != 0 {
+			t.Fatalf("ether has %d particles at step %d", particles, i)
+		}
+	}
+	ca.State[2] ^= 0xFF
+	for i := 0; i < 70; i++ {
+		ca.Step()
+	}
+	if len(ca.Tracker.Particles) == 0 {
+		t.Fatal("no particles were detected after perturbing the ether")
+	}
+}
+
+func TestTracker_Disordered(t *testing.T) {
+	ca := NewCA(110, 100, SpikeThreshold, rand.New(rand.NewSource(1)))
+	for i := range ca.State {
+		ca.State[i] = 0
+	}
+	tracker := NewTracker(NewEtherFilter())
+	particles := tracker.Detect(&ca)
+	if len(particles) != 1 || particles[0].Start != 0 || particles[0].Size != ca.Size {
+		t.Fatalf("a lattice without ether has particles %v", particles)
+	}
+
+	tracker.Particles = []Particle{
+		{ID: 1, Start: 10, Size: 5, Age: 3},
+		{ID: 2, Start: 20, Size: 5, Age: 4},
+	}
+	tracker.Update(&ca)
+	if particle := tracker.Particles[0]; particle.ID != 2 || particle.Age != 5 {
+		t.Fatalf("the particle %+v didn't continue the oldest previous particle", particle)
+	}
+}
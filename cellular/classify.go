@@ -0,0 +1,130 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand"
+
+	"github.com/pointlander/sync/util"
+)
+
+// Classification is a statistical description of an elementary cellular automaton rule
+type Classification struct {
+	Rule uint8
+	// Lambda is Langton's lambda, the fraction of neighborhoods that map to an on cell
+	Lambda float64
+	// Cycles is the number of trials that reached a cycle
+	Cycles int
+	// Transient and Period are the mean transient length and cycle period of the trials that reached a cycle
+	Transient, Period float64
+	// Density and DensityVariance are the mean and variance of the fraction of on cells
+	Density, DensityVariance float64
+	// Damage is the mean fraction of cells that differ at the end after flipping one initial cell
+	Damage float64
+	// Speed is the mean speed in cells per step of the left and right edges of the damage combined
+	Speed float64
+	// Class is the heuristic Wolfram class from 1 to 4
+	Class int
+}
+
+// key converts the state of a cellular automaton into a map key
+func key(state []uint64) string {
+	buffer := make([]byte, 8*len(state))
+	for i, s := range state {
+		binary.LittleEndian.PutUint64(buffer[8*i:], s)
+	}
+	return string(buffer)
+}
+
+// Classify runs a rule from random initial conditions and classifies its behavior,
+// rules that do not cycle are chaotic if damage spreads at least ChaoticSpeed and complex otherwise
+func Classify(rule uint8, size, steps, trials int, rnd *rand.Rand) Classification {
+	c := Classification{
+		Rule:   rule,
+		Lambda: float64(bits.OnesCount8(rule)) / 8,
+	}
+	densities, homogeneous := make([]float64, 0, steps*trials), 0
+	for trial := 0; trial < trials; trial++ {
+		a := NewCA(rule, size, 0, rnd)
+		b := a
+		b.State, b.Next = make([]uint64, len(a.State)), make([]uint64, len(a.Next))
+		copy(b.State, a.State)
+		cell, horizon := size/2, size/4
+		b.State[cell>>6] ^= uint64(1) << uint(cell&0x3F)
+
+		seen := map[string]int{key(a.State): 0}
+		cycled := false
+		for t := 1; t <= steps; t++ {
+			a.Step()
+			b.Step()
+			densities = append(densities, float64(a.On)/float64(size))
+			if t == horizon {
+				left, right := 0, 0
+				for i := 0; i < size; i++ {
+					if a.Bit(i) == b.Bit(i) {
+						continue
+					}
+					if cell-i > left {
+						left = cell - i
+					}
+					if i-cell > right {
+						right = i - cell
+					}
+				}
+				c.Speed += float64(left+right) / float64(horizon)
+			}
+			if cycled {
+				continue
+			}
+			k := key(a.State)
+			if first, ok := seen[k]; ok {
+				cycled = true
+				c.Cycles++
+				c.Transient += float64(first)
+				c.Period += float64(t - first)
+				if a.On == 0 || int(a.On) == size {
+					homogeneous++
+				}
+				continue
+			}
+			seen[k] = t
+		}
+		damage := 0
+		for i := range a.State {
+			damage += bits.OnesCount64(a.State[i] ^ b.State[i])
+		}
+		c.Damage += float64(damage) / float64(size)
+	}
+	if c.Cycles > 0 {
+		c.Transient /= float64(c.Cycles)
+		c.Period /= float64(c.Cycles)
+	}
+	c.Damage /= float64(trials)
+	c.Speed /= float64(trials)
+	c.Density, c.DensityVariance = util.MeanVariance(densities)
+
+	switch {
+	case 2*homogeneous > trials:
+		c.Class = 1
+	case 2*c.Cycles > trials:
+		c.Class = 2
+	case c.Speed >= ChaoticSpeed:
+		c.Class = 3
+	default:
+		c.Class = 4
+	}
+	return c
+}
+
+// ClassifyRules classifies all of the elementary cellular automaton rules
+func ClassifyRules(size, steps, trials int, rnd *rand.Rand) []Classification {
+	classifications := make([]Classification, 256)
+	for rule := range classifications {
+		classifications[rule] = Classify(uint8(rule), size, steps, trials, rnd)
+	}
+	return classifications
+}
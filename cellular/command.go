@@ -7,8 +7,6 @@ package cellular
 import (
 	"encoding/gob"
 	"fmt"
-	"image"
-	"image/png"
 	"math"
 	"math/rand"
 	"os"
@@ -26,13 +24,19 @@ import (
 )
 
 const (
-	Chunks         = 8
-	ChunkSize      = 64
-	CASize         = Chunks * ChunkSize
-	Alpha          = 0.08
-	SpikeFactor    = 2
-	SpikeThreshold = .66
-	NetworkSize    = 7
+	Chunks          = 8
+	ChunkSize       = 64
+	CASize          = Chunks * ChunkSize
+	MinCASize       = 2 * ChunkSize
+	MaxCASize       = 2 * CASize
+	Alpha           = 0.08
+	SpikeFactor     = 2
+	SpikeThreshold  = .66
+	NetworkSize     = 7
+	Seeds           = 2
+	ChaoticSpeed    = 1
+	MaxRefractory   = 100
+	AdaptationDecay = .99
 )
 
 var (
@@ -49,53 +53,27 @@ var (
 	MaxMarkov  = 2 * MaxEntropy
 )
 
-func Bench() {
+// benchNetwork creates the pair of connected cellular automatons used by Bench
+func benchNetwork() Network {
 	network := NewNetwork(1, 2)
+	network.Neurons[0].AddConnection(1)
+	network.Neurons[1].AddConnection(0)
+	return network
+}
+
+func Bench() {
 	iterations := 12000
 	points := make(plotter.XYs, 0, iterations)
-	gray, count := image.NewGray(image.Rect(0, 0, 2*CASize+3, iterations)), 0
-	for i := 0; i < iterations; i++ {
-		for n := range network.Neurons {
-			for _, s := range network.Neurons[n].State {
-				for j := 0; j < ChunkSize; j++ {
-					if s&0x1 == 0 {
-						gray.Pix[count] = 0
-					} else {
-						gray.Pix[count] = 0xFF
-					}
-					s >>= 1
-					count++
-				}
-			}
-			if n == 0 {
-				gray.Pix[count] = 0
-				count++
-				gray.Pix[count] = 0xFF
-				count++
-				gray.Pix[count] = 0
-				count++
-			}
-		}
-		if network.Neurons[0].Test() {
-			network.Swap(0, 1)
-			fmt.Printf("fire 0: %d %f\n", i, network.Neurons[0].Spike)
-		} else if network.Neurons[1].Test() {
-			network.Swap(0, 1)
-			fmt.Printf("fire 1: %d %f\n", i, network.Neurons[1].Spike)
-		}
-		network.Step()
-		points = append(points, plotter.XY{X: float64(i), Y: network.Neurons[0].Spike})
-	}
-
-	out, err := os.Create("ca.png")
-	if err != nil {
-		panic(err)
-	}
-	defer out.Close()
-	err = png.Encode(out, gray)
-	if err != nil {
-		panic(err)
-	}
+	simulator, renderer := NewSimulator(benchNetwork()), NewRenderer()
+	renderer.Attach(simulator)
+	simulator.OnFire(func(s *Simulator, n int) {
+		fmt.Printf("fire %d: %d %f\n", n, s.Steps, s.Network.Neurons[n].Spike)
+	})
+	simulator.OnStep(func(s *Simulator) {
+		points = append(points, plotter.XY{X: float64(s.Steps - 1), Y: s.Network.Neurons[0].Spike})
+	})
+	simulator.Run(iterations)
+	renderer.WritePNG("ca.png")
 
 	p, err := plot.New()
 	if err != nil {
@@ -155,6 +133,25 @@ func Learn() {
 
 }
 
+// LoadNetwork creates a network from a net file, or a ring network if name is empty
+func LoadNetwork(name string) Network {
+	if name != "" {
+		net := ReadNet(name)
+		fmt.Println(net.Thresholds)
+		fmt.Println(net.Sizes)
+		return net.NewNetwork(1)
+	}
+	network := NewNetwork(1, NetworkSize)
+	for i := range network.Neurons {
+		network.Neurons[i].AddConnection((i + (NetworkSize - 1)) % NetworkSize)
+		network.Neurons[i].AddConnection((i + 1) % NetworkSize)
+	}
+	for i, note := range Notes {
+		network.Neurons[i].Note = note
+	}
+	return network
+}
+
 func Inference(name string) {
 	out, err := os.Create("music.midi")
 	if err != nil {
@@ -166,69 +163,21 @@ func Inference(name string) {
 	wr.TrackSequenceName("music")
 	defer wr.EndOfTrack()
 
-	network := NewNetwork(1, NetworkSize)
-	if name != "" {
-		net := Net{}
-		in, err := os.Open(name)
-		if err != nil {
-			panic(err)
-		}
-		defer in.Close()
-		decoder := gob.NewDecoder(in)
-		err = decoder.Decode(&net)
-		if err != nil {
-			panic(err)
-		}
-
-		k := 0
-		for i := 0; i < NetworkSize; i++ {
-			for j := 0; j < NetworkSize; j++ {
-				if i != j && net.Connections[k] {
-					network.Neurons[i].AddConnection(j)
-				}
-				k++
-			}
-		}
-		fmt.Println(net.Thresholds)
-		for i, value := range net.Thresholds {
-			network.Neurons[i].Threshold = value
-		}
-	} else {
-		for i := range network.Neurons {
-			network.Neurons[i].AddConnection((i + (NetworkSize - 1)) % NetworkSize)
-			network.Neurons[i].AddConnection((i + 1) % NetworkSize)
-		}
-	}
-	for i, note := range Notes {
-		network.Neurons[i].Note = note
-	}
+	network := LoadNetwork(name)
 
-	generation := 0
 	notes := make([]uint8, 0, 256)
-	for generation < 300000 {
-		for n := range network.Neurons {
-			if network.Neurons[n].Test() {
-				m, max := n, 0.0
-				for _, c := range network.Neurons[n].Connections {
-					if complexity := network.Neurons[c].Complexity; complexity > max {
-						m, max = c, complexity
-					}
-				}
-				network.Swap(n, m)
-				fmt.Printf("fire %d: %d %f\n", n, generation, network.Neurons[n].Spike)
-
-				if note := network.Neurons[n].Note; note > 0 {
-					wr.SetDelta(ticks.Ticks8th())
-					wr.NoteOn(note, 50)
-					wr.SetDelta(ticks.Ticks8th())
-					wr.NoteOff(note)
-					notes = append(notes, note)
-				}
-			}
-		}
-		network.Step()
-		generation++
-	}
+	simulator := NewSimulator(network)
+	simulator.OnFire(func(s *Simulator, n int) {
+		fmt.Printf("fire %d: %d %f\n", n, s.Steps, s.Network.Neurons[n].Spike)
+	})
+	simulator.OnNote(func(s *Simulator, n int, note uint8) {
+		wr.SetDelta(ticks.Ticks8th())
+		wr.NoteOn(note, 50)
+		wr.SetDelta(ticks.Ticks8th())
+		wr.NoteOff(note)
+		notes = append(notes, note)
+	})
+	simulator.Run(300000)
 
 	length := len(notes)
 	entropyPoints, markovPoints := make(plotter.XYs, 0, length), make(plotter.XYs, 0, length)
@@ -288,3 +237,43 @@ func Inference(name string) {
 		panic(err)
 	}
 }
+
+// Analyze runs damage spreading analysis on a network
+func Analyze(name string) {
+	network := LoadNetwork(name)
+	curve, lyapunov := Damage(&network, 16, 1000, rand.New(rand.NewSource(1)))
+	fmt.Printf("lyapunov=%f\n", lyapunov)
+
+	points := make(plotter.XYs, 0, len(curve))
+	for t, d := range curve {
+		points = append(points, plotter.XY{X: float64(t), Y: d})
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+
+	p.Title.Text = "damage"
+	p.X.Label.Text = "time"
+	p.Y.Label.Text = "hamming distance"
+
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		panic(err)
+	}
+	p.Add(line)
+
+	err = p.Save(8*vg.Inch, 8*vg.Inch, "damage.png")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Rules classifies the elementary cellular automaton rules
+func Rules() {
+	for _, c := range ClassifyRules(401, 2048, 8, rand.New(rand.NewSource(1))) {
+		fmt.Printf("rule=%d class=%d lambda=%f cycles=%d transient=%f period=%f density=%f variance=%f damage=%f speed=%f\n",
+			c.Rule, c.Class, c.Lambda, c.Cycles, c.Transient, c.Period, c.Density, c.DensityVariance, c.Damage, c.Speed)
+	}
+}
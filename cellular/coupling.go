@@ -0,0 +1,93 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math/rand"
+)
+
+// Coupling is a policy for passing a message from one cellular automaton to another
+type Coupling interface {
+	Couple(from, to *CA, rnd *rand.Rand)
+}
+
+// SwapCoupling exchanges a random chunk between two cellular automatons
+type SwapCoupling struct{}
+
+// Couple exchanges a random chunk of from with a random chunk of to
+func (SwapCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	a, b := rnd.Intn(len(to.State)), rnd.Intn(len(from.State))
+	to.State[a], from.State[b] = from.State[b], to.State[a]
+	to.Trim()
+	from.Trim()
+}
+
+// XORCoupling injects a random chunk into another cellular automaton with xor
+type XORCoupling struct{}
+
+// Couple xors a random chunk of from into a random chunk of to
+func (XORCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	a, b := rnd.Intn(len(to.State)), rnd.Intn(len(from.State))
+	to.State[a] ^= from.State[b]
+	to.Trim()
+}
+
+// ORCoupling injects a random chunk into another cellular automaton with or
+type ORCoupling struct{}
+
+// Couple ors a random chunk of from into a random chunk of to
+func (ORCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	a, b := rnd.Intn(len(to.State)), rnd.Intn(len(from.State))
+	to.State[a] |= from.State[b]
+	to.Trim()
+}
+
+// CopyCoupling copies a random chunk into another cellular automaton
+type CopyCoupling struct{}
+
+// Couple overwrites a random chunk of to with a random chunk of from
+func (CopyCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	a, b := rnd.Intn(len(to.State)), rnd.Intn(len(from.State))
+	to.State[a] = from.State[b]
+	to.Trim()
+}
+
+// DiffusionCoupling copies individual cells into another cellular automaton
+type DiffusionCoupling struct {
+	Bits int
+}
+
+// Couple copies Bits random cells of from into the same cells of to
+func (d DiffusionCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	for i := 0; i < d.Bits; i++ {
+		cell := rnd.Intn(to.Size)
+		mask := uint64(1) << uint(cell&0x3F)
+		if from.Bit(cell%from.Size) == 0 {
+			to.State[cell>>6] &^= mask
+		} else {
+			to.State[cell>>6] |= mask
+		}
+	}
+}
+
+// PartialSwapCoupling exchanges a random subset of the cells of a random chunk
+type PartialSwapCoupling struct {
+	Weight float64
+}
+
+// Couple exchanges each cell of two random chunks with probability Weight
+func (p PartialSwapCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	a, b := rnd.Intn(len(to.State)), rnd.Intn(len(from.State))
+	mask := uint64(0)
+	for i := uint(0); i < 64; i++ {
+		if rnd.Float64() < p.Weight {
+			mask |= 1 << i
+		}
+	}
+	x, y := to.State[a], from.State[b]
+	to.State[a], from.State[b] = x&^mask|y&mask, y&^mask|x&mask
+	to.Trim()
+	from.Trim()
+}
@@ -0,0 +1,202 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// used returns the mask of the cells of chunk i that are inside of a cellular automaton of size cells
+func used(size, i int) uint64 {
+	if tail := uint(size & 0x3F); tail != 0 && i == (size-1)>>6 {
+		return (1 << tail) - 1
+	}
+	return ^uint64(0)
+}
+
+func clone(ca CA) CA {
+	ca.State = append([]uint64(nil), ca.State...)
+	return ca
+}
+
+func TestCA_Trim(t *testing.T) {
+	for _, size := range [...]int{1, 63, 64, 65, 100, 128, 130} {
+		ca := NewCA(110, size, SpikeThreshold, rand.New(rand.NewSource(1)))
+		for i := range ca.State {
+			ca.State[i] = ^uint64(0)
+		}
+		ca.Trim()
+		for i, chunk := range ca.State {
+			if chunk != used(size, i) {
+				t.Fatalf("size %d chunk %d: %x != %x", size, i, chunk, used(size, i))
+			}
+		}
+	}
+}
+
+func TestChunkCouplings(t *testing.T) {
+	couplings := [...]struct {
+		name     string
+		coupling Coupling
+		// expect computes the new to and from chunks from the old chunks x of to and y of from
+		expect func(x, y uint64) (uint64, uint64)
+	}{
+		{"swap", SwapCoupling{}, func(x, y uint64) (uint64, uint64) { return y, x }},
+		{"xor", XORCoupling{}, func(x, y uint64) (uint64, uint64) { return x ^ y, y }},
+		{"or", ORCoupling{}, func(x, y uint64) (uint64, uint64) { return x | y, y }},
+		{"copy", CopyCoupling{}, func(x, y uint64) (uint64, uint64) { return y, y }},
+		{"partial swap", PartialSwapCoupling{Weight: 1}, func(x, y uint64) (uint64, uint64) { return y, x }},
+	}
+	for _, c := range couplings {
+		rnd := rand.New(rand.NewSource(1))
+		for seed := int64(1); seed <= 64; seed++ {
+			from, to := NewCA(110, 100, SpikeThreshold, rnd), NewCA(110, 130, SpikeThreshold, rnd)
+			for i := range from.State {
+				from.State[i] = rnd.Uint64() & used(from.Size, i)
+			}
+			for i := range to.State {
+				to.State[i] = rnd.Uint64() & used(to.Size, i)
+			}
+			f, g := clone(from), clone(to)
+			c.coupling.Couple(&from, &to, rand.New(rand.NewSource(seed)))
+
+			draws := rand.New(rand.NewSource(seed))
+			a, b := draws.Intn(len(to.State)), draws.Intn(len(from.State))
+			x, y := c.expect(g.State[a], f.State[b])
+			g.State[a], f.State[b] = x, y
+			for i := range g.State {
+				if expected := g.State[i] & used(to.Size, i); to.State[i] != expected {
+					t.Fatalf("%s seed %d: to chunk %d is %x not %x", c.name, seed, i, to.State[i], expected)
+				}
+			}
+			for i := range f.State {
+				if expected := f.State[i] & used(from.Size, i); from.State[i] != expected {
+					t.Fatalf("%s seed %d: from chunk %d is %x not %x", c.name, seed, i, from.State[i], expected)
+				}
+			}
+		}
+	}
+}
+
+func TestPartialSwapCoupling(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, weight := range [...]float64{0, .5} {
+		for seed := int64(1); seed <= 64; seed++ {
+			from, to := NewCA(110, 100, SpikeThreshold, rnd), NewCA(110, 130, SpikeThreshold, rnd)
+			f, g := clone(from), clone(to)
+			PartialSwapCoupling{Weight: weight}.Couple(&from, &to, rand.New(rand.NewSource(seed)))
+
+			draws := rand.New(rand.NewSource(seed))
+			a, b := draws.Intn(len(to.State)), draws.Intn(len(from.State))
+			mask := uint64(0)
+			for i := uint(0); i < 64; i++ {
+				if draws.Float64() < weight {
+					mask |= 1 << i
+				}
+			}
+			x, y := g.State[a], f.State[b]
+			g.State[a], f.State[b] = x&^mask|y&mask, y&^mask|x&mask
+			for i := range g.State {
+				if expected := g.State[i] & used(to.Size, i); to.State[i] != expected {
+					t.Fatalf("weight %f seed %d: to chunk %d is %x not %x", weight, seed, i, to.State[i], expected)
+				}
+			}
+			for i := range f.State {
+				if expected := f.State[i] & used(from.Size, i); from.State[i] != expected {
+					t.Fatalf("weight %f seed %d: from chunk %d is %x not %x", weight, seed, i, from.State[i], expected)
+				}
+			}
+		}
+	}
+}
+
+func TestDiffusionCoupling(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, sizes := range [...][2]int{{100, 130}, {130, 100}, {CASize, CASize}} {
+		from, to := NewCA(110, sizes[0], SpikeThreshold, rnd), NewCA(110, sizes[1], SpikeThreshold, rnd)
+		f, g := clone(from), clone(to)
+		coupling := DiffusionCoupling{Bits: 3 * to.Size}
+		coupling.Couple(&from, &to, rand.New(rand.NewSource(2)))
+
+		for i := range from.State {
+			if from.State[i] != f.State[i] {
+				t.Fatalf("sizes %v: from chunk %d changed", sizes, i)
+			}
+		}
+		copied := make([]bool, to.Size)
+		draws := rand.New(rand.NewSource(2))
+		for i := 0; i < coupling.Bits; i++ {
+			copied[draws.Intn(to.Size)] = true
+		}
+		for cell := 0; cell < to.Size; cell++ {
+			expected := g.Bit(cell)
+			if copied[cell] {
+				expected = from.Bit(cell % from.Size)
+			}
+			if bit := to.Bit(cell); bit != expected {
+				t.Fatalf("sizes %v: cell %d is %d not %d", sizes, cell, bit, expected)
+			}
+		}
+		last := len(to.State) - 1
+		if to.State[last]&^used(to.Size, last) != 0 {
+			t.Fatalf("sizes %v: cells past the end were written", sizes)
+		}
+	}
+}
+
+func TestSwapCoupling_Swap(t *testing.T) {
+	a, b := NewNetwork(1, NetworkSize), NewNetwork(1, NetworkSize)
+	for i := 0; i < 64; i++ {
+		m, n := i%NetworkSize, (i+1)%NetworkSize
+		a.Swap(m, n)
+
+		x, y, neurons := b.Rnd.Intn(Chunks), b.Rnd.Intn(Chunks), b.Neurons
+		neurons[n].State[x], neurons[m].State[y] = neurons[m].State[y], neurons[n].State[x]
+	}
+	for i := range a.Neurons {
+		for j := range a.Neurons[i].State {
+			if a.Neurons[i].State[j] != b.Neurons[i].State[j] {
+				t.Fatalf("neuron %d chunk %d differs from the old swap", i, j)
+			}
+		}
+	}
+}
+
+// namedCoupling records its name each time it is used
+type namedCoupling struct {
+	name string
+	log  *[]string
+}
+
+func (c namedCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	*c.log = append(*c.log, c.name)
+}
+
+func TestCA_AddCoupledConnection(t *testing.T) {
+	var log []string
+	network := NewNetworkSizes(1, []int{CASize, CASize, CASize})
+	network.Coupling = namedCoupling{"default", &log}
+	connection := namedCoupling{"connection", &log}
+	network.Neurons[0].AddCoupledConnection(1, connection)
+	network.Neurons[0].AddConnection(2)
+	network.Neurons[1].AddConnection(0)
+
+	if coupling := network.Neurons[0].Coupling(1); coupling != connection {
+		t.Fatalf("the coupling for 0 to 1 is %v", coupling)
+	}
+	if coupling := network.Neurons[0].Coupling(2); coupling != nil {
+		t.Fatalf("the coupling for 0 to 2 is %v not nil", coupling)
+	}
+	network.Swap(0, 1)
+	network.Swap(0, 2)
+	network.Swap(1, 0)
+	expected := []string{"connection", "default", "default"}
+	for i, name := range expected {
+		if log[i] != name {
+			t.Fatalf("swap %d used the %s coupling not the %s coupling", i, log[i], name)
+		}
+	}
+}
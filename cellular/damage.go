@@ -0,0 +1,90 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// Hamming computes the number of cells that differ between two networks with the same sizes
+func Hamming(a, b *Network) int {
+	distance := 0
+	for n := range a.Neurons {
+		x, y := a.Neurons[n].State, b.Neurons[n].State
+		for i := range x {
+			distance += bits.OnesCount64(x[i] ^ y[i])
+		}
+	}
+	return distance
+}
+
+// Cells is the total number of cells in the network
+func (network *Network) Cells() int {
+	cells := 0
+	for n := range network.Neurons {
+		cells += network.Neurons[n].Size
+	}
+	return cells
+}
+
+// Flip flips a cell of a cellular automaton
+func (network *Network) Flip(n, cell int) {
+	network.Neurons[n].State[cell>>6] ^= uint64(1) << uint(cell&0x3F)
+}
+
+// Spread iterates two networks side by side and returns the hamming distance between them before and after each step
+func Spread(a, b *Network, steps int) []int {
+	distances := make([]int, steps+1)
+	distances[0] = Hamming(a, b)
+	for t := 1; t <= steps; t++ {
+		a.Iterate(nil)
+		b.Iterate(nil)
+		distances[t] = Hamming(a, b)
+	}
+	return distances
+}
+
+// Damage runs copies of the network side by side with copies that have one random cell flipped,
+// it returns the mean fraction of differing cells after each step and an estimate of the
+// maximal lyapunov exponent per step from the growth of the mean damage before saturation
+func Damage(network *Network, trials, steps int, rnd *rand.Rand) (curve []float64, lyapunov float64) {
+	curve = make([]float64, steps+1)
+	cells := float64(network.Cells())
+	for trial := 0; trial < trials; trial++ {
+		a, b := network.Clone(), network.Clone()
+		n := rnd.Intn(len(b.Neurons))
+		b.Flip(n, rnd.Intn(b.Neurons[n].Size))
+		for t, distance := range Spread(&a, &b, steps) {
+			curve[t] += float64(distance)
+		}
+	}
+	for t := range curve {
+		curve[t] /= float64(trials) * cells
+	}
+	return curve, Lyapunov(curve, .25)
+}
+
+// Lyapunov estimates the exponential growth rate per step of a damage curve with
+// a least squares fit of the log damage up to the first step where it is zero or exceeds saturation
+func Lyapunov(curve []float64, saturation float64) float64 {
+	var sumX, sumY, sumXY, sumXX, n float64
+	for t, d := range curve {
+		if d <= 0 || (t > 0 && d > saturation) {
+			break
+		}
+		x, y := float64(t), math.Log(d)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+		n++
+	}
+	if n < 2 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
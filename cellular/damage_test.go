@@ -0,0 +1,59 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLyapunov(t *testing.T) {
+	curve := make([]float64, 100)
+	for i := range curve {
+		curve[i] = 1e-4 * math.Exp(.05*float64(i))
+	}
+	if lyapunov := Lyapunov(curve, .25); math.Abs(lyapunov-.05) > 1e-9 {
+		t.Fatalf("lyapunov is %f not .05", lyapunov)
+	}
+
+	saturated := make([]float64, len(curve))
+	for i := range saturated {
+		saturated[i] = math.Min(1e-4*math.Exp(.1*float64(i)), .5)
+	}
+	if lyapunov := Lyapunov(saturated, .25); math.Abs(lyapunov-.1) > 1e-9 {
+		t.Fatalf("lyapunov is %f not .1 before saturation", lyapunov)
+	}
+
+	healed := []float64{1e-3, 2e-3, 4e-3, 0, 1e-1, 2e-1}
+	if lyapunov := Lyapunov(healed, .25); math.Abs(lyapunov-math.Ln2) > 1e-9 {
+		t.Fatalf("lyapunov is %f not ln 2 before the damage healed", lyapunov)
+	}
+
+	if lyapunov := Lyapunov([]float64{1e-3, 0, 1e-2}, .25); lyapunov != 0 {
+		t.Fatalf("lyapunov is %f for a single point", lyapunov)
+	}
+	if lyapunov := Lyapunov([]float64{.5, .6}, .25); lyapunov != 0 {
+		t.Fatalf("lyapunov is %f for a curve that starts saturated", lyapunov)
+	}
+}
+
+func TestDamage(t *testing.T) {
+	network := NewNetwork(1, 3)
+	for i := range network.Neurons {
+		network.Neurons[i].AddConnection((i + 1) % 3)
+	}
+	a, b := network.Clone(), network.Clone()
+	for i, distance := range Spread(&a, &b, 500) {
+		if distance != 0 {
+			t.Fatalf("identical networks differ by %d cells at step %d", distance, i)
+		}
+	}
+
+	curve, _ := Damage(&network, 4, 100, rand.New(rand.NewSource(1)))
+	if expected := 1 / float64(network.Cells()); math.Abs(curve[0]-expected) > 1e-12 {
+		t.Fatalf("initial damage is %f not %f", curve[0], expected)
+	}
+}
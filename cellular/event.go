@@ -0,0 +1,47 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"time"
+
+	"github.com/pointlander/sync/event"
+)
+
+// RunEvents runs the network with a discrete event scheduler until the simulated duration,
+// each cellular automaton updates on its own clock and a firing cellular automaton sends a copy of its state
+// to each of its targets, the coupling is applied with the copy when the message arrives after delay
+// and changes to the copy are discarded, fired is called for each spike
+func (network *Network) RunEvents(clocks []event.Clock, delay, duration time.Duration, seed int64, fired func(n int, t time.Duration)) {
+	scheduler, messages, id := event.NewScheduler(clocks, seed), make(map[int64]*CA), int64(0)
+	for {
+		e, ok := scheduler.Next(duration)
+		if !ok {
+			break
+		}
+		if e.Kind == event.Message {
+			network.Deliver(messages[e.Value], e.From, e.Node)
+			delete(messages, e.Value)
+			continue
+		}
+		n, ca := e.Node, &network.Neurons[e.Node]
+		if ca.Test() {
+			ca.Spiked()
+			for _, m := range network.Target.Select(network, n) {
+				state := make([]uint64, len(ca.State))
+				copy(state, ca.State)
+				messages[id] = &CA{Rule: ca.Rule, Size: ca.Size, State: state}
+				scheduler.Send(n, m, delay, id)
+				id++
+			}
+			if fired != nil {
+				fired(n, e.Time)
+			}
+		} else {
+			ca.Rest()
+		}
+		ca.Step()
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+// Input injects a time series of bit patterns into a chunk of a cellular automaton,
+// the bits set in Mask are overwritten and a zero Mask overwrites the whole chunk,
+// a Chunk past the end of a smaller cellular automaton is ignored
+type Input struct {
+	Neuron, Chunk int
+	Mask          uint64
+	Series        []uint64
+}
+
+// Inject injects the pattern for time t, nothing is injected after the series ends
+func (i *Input) Inject(network *Network, t int) {
+	if t < 0 || t >= len(i.Series) {
+		return
+	}
+	ca := &network.Neurons[i.Neuron]
+	if i.Chunk < 0 || i.Chunk >= len(ca.State) {
+		return
+	}
+	mask := i.Mask
+	if mask == 0 {
+		mask = ^uint64(0)
+	}
+	ca.State[i.Chunk] = ca.State[i.Chunk]&^mask | i.Series[t]&mask
+	ca.Trim()
+}
+
+// Drive injects the inputs for time t into the network
+func (network *Network) Drive(inputs []Input, t int) {
+	for i := range inputs {
+		inputs[i].Inject(network, t)
+	}
+}
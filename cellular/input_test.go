@@ -0,0 +1,67 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"testing"
+)
+
+func TestInput_Inject(t *testing.T) {
+	network := NewNetworkSizes(1, []int{CASize, 100})
+	state := append([]uint64(nil), network.Neurons[0].State...)
+	input := Input{Neuron: 0, Chunk: 3, Mask: 0xF0, Series: []uint64{0xAA, 0x55}}
+	input.Inject(&network, 0)
+	if expected := state[3]&^0xF0 | 0xA0; network.Neurons[0].State[3] != expected {
+		t.Fatalf("masked injection gave %x not %x", network.Neurons[0].State[3], expected)
+	}
+	input.Inject(&network, 1)
+	if expected := state[3]&^0xF0 | 0x50; network.Neurons[0].State[3] != expected {
+		t.Fatalf("masked injection gave %x not %x", network.Neurons[0].State[3], expected)
+	}
+	for i, chunk := range network.Neurons[0].State {
+		if i != 3 && chunk != state[i] {
+			t.Fatalf("chunk %d changed", i)
+		}
+	}
+
+	state[3] = network.Neurons[0].State[3]
+	for _, step := range [...]int{-1, 2, 100} {
+		input.Inject(&network, step)
+	}
+	if network.Neurons[0].State[3] != state[3] {
+		t.Fatal("an input was injected outside of the series")
+	}
+
+	whole := Input{Neuron: 1, Chunk: 1, Series: []uint64{^uint64(0)}}
+	whole.Inject(&network, 0)
+	if chunk := network.Neurons[1].State[1]; chunk != used(100, 1) {
+		t.Fatalf("a zero mask gave %x not %x", chunk, used(100, 1))
+	}
+
+	small := append([]uint64(nil), network.Neurons[1].State...)
+	past := Input{Neuron: 1, Chunk: 3, Series: []uint64{^uint64(0)}}
+	past.Inject(&network, 0)
+	for i, chunk := range network.Neurons[1].State {
+		if chunk != small[i] {
+			t.Fatalf("a chunk past the end changed chunk %d", i)
+		}
+	}
+}
+
+func TestNetwork_Drive(t *testing.T) {
+	network := NewNetwork(1, 2)
+	state := network.Neurons[0].State[0]
+	inputs := []Input{
+		{Neuron: 0, Chunk: 0, Series: []uint64{^state}},
+		{Neuron: 1, Chunk: 2, Series: []uint64{0, 2}},
+	}
+	network.Drive(inputs, 1)
+	if network.Neurons[1].State[2] != 2 {
+		t.Fatalf("the second input was not injected: %x", network.Neurons[1].State[2])
+	}
+	if network.Neurons[0].State[0] != state {
+		t.Fatal("the first input was injected after its series ended")
+	}
+}
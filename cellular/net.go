@@ -5,7 +5,9 @@
 package cellular
 
 import (
+	"encoding/gob"
 	"math/rand"
+	"os"
 
 	"github.com/pointlander/sync/slices"
 	"github.com/pointlander/sync/util"
@@ -16,10 +18,25 @@ import (
 type Net struct {
 	Connections slices.Bool
 	Thresholds  slices.Float64
+	Sizes       slices.Int
+	Alphas      slices.Float64
+	Detectors   slices.Uint8
+	Parameters  slices.Float64
+	Absolute    slices.Int
+	Relative    slices.Int
+	Adaptation  slices.Float64
 }
 
-func (n *Net) fitness(seed int) float64 {
-	network, k := NewNetwork(seed, NetworkSize), 0
+// NewNetwork creates a network of cellular automatons from the net
+func (n *Net) NewNetwork(seed int) Network {
+	sizes := make([]int, NetworkSize)
+	for i := range sizes {
+		sizes[i] = CASize
+		if i < len(n.Sizes) {
+			sizes[i] = n.Sizes[i]
+		}
+	}
+	network, k := NewNetworkSizes(seed, sizes), 0
 	for i := 0; i < NetworkSize; i++ {
 		for j := 0; j < NetworkSize; j++ {
 			if i != j && n.Connections[k] {
@@ -31,35 +48,51 @@ func (n *Net) fitness(seed int) float64 {
 	for i, value := range n.Thresholds {
 		network.Neurons[i].Threshold = value
 	}
+	for i, value := range n.Alphas {
+		network.Neurons[i].Alpha = value
+	}
+	for i, value := range n.Absolute {
+		network.Neurons[i].Absolute = value
+	}
+	for i, value := range n.Relative {
+		network.Neurons[i].Relative = value
+	}
+	for i, value := range n.Adaptation {
+		network.Neurons[i].Adaptation = value
+	}
+	if len(n.Detectors) == len(n.Parameters) {
+		for i, kind := range n.Detectors {
+			network.Neurons[i].SetDetector(NewDetector(kind, n.Parameters[i]))
+		}
+	}
 	for i, note := range Notes {
 		network.Neurons[i].Note = note
 	}
+	return network
+}
 
-	markov := util.Markov{}
-	for generation := 0; generation < 40000; generation++ {
-		for n := range network.Neurons {
-			if network.Neurons[n].Test() {
-				m, max := n, 0.0
-				for _, c := range network.Neurons[n].Connections {
-					if complexity := network.Neurons[c].Complexity; complexity > max {
-						m, max = c, complexity
-					}
-				}
-				network.Swap(n, m)
-
-				if note := network.Neurons[n].Note; note > 0 {
-					markov.Add(note)
-				}
-			}
-		}
-		network.Step()
-	}
+func (n *Net) fitness(seed int) float64 {
+	simulator, markov := NewSimulator(n.NewNetwork(seed)), util.Markov{}
+	simulator.OnNote(func(s *Simulator, n int, note uint8) {
+		markov.Add(note)
+	})
+	simulator.Run(40000)
 
 	return markov.Entropy() / MaxMarkov
 }
 
+// Fitness computes the mean and variance of the fitness across seeds 1 through seeds
+func (n *Net) Fitness(seeds int) (mean, variance float64) {
+	values := make([]float64, seeds)
+	for i := range values {
+		values[i] = n.fitness(i + 1)
+	}
+	return util.MeanVariance(values)
+}
+
 func (n *Net) Evaluate() (float64, error) {
-	fitness := (n.fitness(1)+n.fitness(2))/2 - .8
+	fitness, _ := n.Fitness(Seeds)
+	fitness -= .8
 	//fmt.Println(fitness)
 	return fitness * fitness, nil
 }
@@ -67,24 +100,75 @@ func (n *Net) Evaluate() (float64, error) {
 func (n *Net) Mutate(rng *rand.Rand) {
 	eaopt.MutPermute(n.Connections, 1, rng)
 	eaopt.MutPermute(n.Thresholds, 1, rng)
+	eaopt.MutPermute(n.Sizes, 1, rng)
+	eaopt.MutPermute(n.Alphas, 1, rng)
+	eaopt.MutPermute(n.Detectors, 1, rng)
+	eaopt.MutPermute(n.Parameters, 1, rng)
+	eaopt.MutPermute(n.Absolute, 1, rng)
+	eaopt.MutPermute(n.Relative, 1, rng)
+	eaopt.MutPermute(n.Adaptation, 1, rng)
 }
 
 func (n *Net) Crossover(r eaopt.Genome, rng *rand.Rand) {
 	eaopt.CrossGNX(n.Connections, r.(*Net).Connections, 1, rng)
 	eaopt.CrossGNX(n.Thresholds, r.(*Net).Thresholds, 1, rng)
+	eaopt.CrossGNX(n.Sizes, r.(*Net).Sizes, 1, rng)
+	eaopt.CrossGNX(n.Alphas, r.(*Net).Alphas, 1, rng)
+	eaopt.CrossGNX(n.Detectors, r.(*Net).Detectors, 1, rng)
+	eaopt.CrossGNX(n.Parameters, r.(*Net).Parameters, 1, rng)
+	eaopt.CrossGNX(n.Absolute, r.(*Net).Absolute, 1, rng)
+	eaopt.CrossGNX(n.Relative, r.(*Net).Relative, 1, rng)
+	eaopt.CrossGNX(n.Adaptation, r.(*Net).Adaptation, 1, rng)
 }
 
 func (n *Net) Clone() eaopt.Genome {
 	connections := make(slices.Bool, len(n.Connections))
 	thresholds := make(slices.Float64, len(n.Thresholds))
+	sizes := make(slices.Int, len(n.Sizes))
+	alphas := make(slices.Float64, len(n.Alphas))
+	detectors := make(slices.Uint8, len(n.Detectors))
+	parameters := make(slices.Float64, len(n.Parameters))
+	absolute := make(slices.Int, len(n.Absolute))
+	relative := make(slices.Int, len(n.Relative))
+	adaptation := make(slices.Float64, len(n.Adaptation))
 	copy(connections, n.Connections)
 	copy(thresholds, n.Thresholds)
+	copy(sizes, n.Sizes)
+	copy(alphas, n.Alphas)
+	copy(detectors, n.Detectors)
+	copy(parameters, n.Parameters)
+	copy(absolute, n.Absolute)
+	copy(relative, n.Relative)
+	copy(adaptation, n.Adaptation)
 	return &Net{
 		Connections: connections,
 		Thresholds:  thresholds,
+		Sizes:       sizes,
+		Alphas:      alphas,
+		Detectors:   detectors,
+		Parameters:  parameters,
+		Absolute:    absolute,
+		Relative:    relative,
+		Adaptation:  adaptation,
 	}
 }
 
+// ReadNet reads a net from a file
+func ReadNet(name string) *Net {
+	net := Net{}
+	in, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	decoder := gob.NewDecoder(in)
+	err = decoder.Decode(&net)
+	if err != nil {
+		panic(err)
+	}
+	return &net
+}
+
 func NetFactory(rnd *rand.Rand) eaopt.Genome {
 	connections := make(slices.Bool, NetworkSize*NetworkSize)
 	k := 0
@@ -98,8 +182,37 @@ func NetFactory(rnd *rand.Rand) eaopt.Genome {
 	for i := range thresholds {
 		thresholds[i] = rnd.Float64()
 	}
+	sizes := make(slices.Int, NetworkSize)
+	for i := range sizes {
+		sizes[i] = MinCASize + rnd.Intn(MaxCASize-MinCASize+1)
+	}
+	alphas := make(slices.Float64, NetworkSize)
+	for i := range alphas {
+		alphas[i] = Alpha/2 + 1.5*Alpha*rnd.Float64()
+	}
+	detectors := make(slices.Uint8, NetworkSize)
+	parameters := make(slices.Float64, NetworkSize)
+	for i := range detectors {
+		detectors[i] = uint8(rnd.Intn(Detectors))
+		parameters[i] = rnd.Float64()
+	}
+	absolute := make(slices.Int, NetworkSize)
+	relative := make(slices.Int, NetworkSize)
+	adaptation := make(slices.Float64, NetworkSize)
+	for i := range absolute {
+		absolute[i] = rnd.Intn(MaxRefractory / 4)
+		relative[i] = rnd.Intn(MaxRefractory / 4)
+		adaptation[i] = rnd.Float64()
+	}
 	return &Net{
 		Connections: connections,
 		Thresholds:  thresholds,
+		Sizes:       sizes,
+		Alphas:      alphas,
+		Detectors:   detectors,
+		Parameters:  parameters,
+		Absolute:    absolute,
+		Relative:    relative,
+		Adaptation:  adaptation,
 	}
 }
@@ -6,39 +6,87 @@ package cellular
 
 import (
 	"math/rand"
+
+	"github.com/pointlander/sync/util"
 )
 
 // Network is a network of cellular automatons
 type Network struct {
-	Neurons []CA
-	Rnd     *rand.Rand
-	Next    []uint64
+	Neurons  []CA
+	Rnd      *rand.Rand
+	Source   *util.Source
+	Coupling Coupling
+	Target   Target
 }
 
-// NewNetwork creates a new network of cellular automatons
+// NewNetwork creates a new network of cellular automatons, seed determines the initial states and swaps
 func NewNetwork(seed, size int) Network {
-	rnd, neurons := rand.New(rand.NewSource(1)), make([]CA, size)
-	for i := range neurons {
-		neurons[i] = NewCA(110, Chunks, SpikeThreshold, rnd)
+	sizes := make([]int, size)
+	for i := range sizes {
+		sizes[i] = CASize
+	}
+	return NewNetworkSizes(seed, sizes)
+}
+
+// NewNetworkSizes creates a new network of cellular automatons with the given number of cells each
+func NewNetworkSizes(seed int, sizes []int) Network {
+	source := util.NewSource(int64(seed))
+	rnd, neurons := rand.New(source), make([]CA, len(sizes))
+	for i, size := range sizes {
+		neurons[i] = NewCA(110, size, SpikeThreshold, rnd)
 	}
 	return Network{
-		Neurons: neurons,
-		Rnd:     rnd,
-		Next:    make([]uint64, Chunks),
+		Neurons:  neurons,
+		Rnd:      rnd,
+		Source:   source,
+		Coupling: SwapCoupling{},
+		Target:   MaxComplexityTarget{},
 	}
 }
 
 // Step steps all of the cellular automatons in the network
 func (network *Network) Step() {
-	neurons, next := network.Neurons, network.Next
+	neurons := network.Neurons
 	for n := range neurons {
-		next = neurons[n].Step(next)
+		neurons[n].Step()
 	}
-	network.Next = next
 }
 
-// Swap sends a message between two cellular automatons
+// Swap sends a message from cellular automaton m to cellular automaton n
 func (network *Network) Swap(m, n int) {
-	a, b, neurons := network.Rnd.Intn(Chunks), network.Rnd.Intn(Chunks), network.Neurons
-	neurons[n].State[a], neurons[m].State[b] = neurons[m].State[b], neurons[n].State[a]
+	network.Deliver(&network.Neurons[m], m, n)
+}
+
+// Deliver applies the coupling of the connection from cellular automaton m to cellular automaton n
+// with from in place of m
+func (network *Network) Deliver(from *CA, m, n int) {
+	coupling := network.Neurons[m].Coupling(n)
+	if coupling == nil {
+		coupling = network.Coupling
+	}
+	coupling.Couple(from, &network.Neurons[n], network.Rnd)
+}
+
+// Fire sends messages from cellular automaton n to the targets it selects
+func (network *Network) Fire(n int) {
+	for _, m := range network.Target.Select(network, n) {
+		network.Swap(n, m)
+	}
+}
+
+// Iterate fires the cellular automatons that are spiking and then steps the network,
+// fired is called for each cellular automaton that fires
+func (network *Network) Iterate(fired func(n int)) {
+	for n := range network.Neurons {
+		if !network.Neurons[n].Test() {
+			network.Neurons[n].Rest()
+			continue
+		}
+		network.Neurons[n].Spiked()
+		network.Fire(n)
+		if fired != nil {
+			fired(n)
+		}
+	}
+	network.Step()
 }
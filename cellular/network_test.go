@@ -0,0 +1,153 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pointlander/sync/event"
+)
+
+func TestNewNetwork(t *testing.T) {
+	equal := func(a, b Network) bool {
+		for i := range a.Neurons {
+			if a.Neurons[i].String() != b.Neurons[i].String() {
+				return false
+			}
+		}
+		return true
+	}
+	if !equal(NewNetwork(1, 2), NewNetwork(1, 2)) {
+		t.Fatal("same seed should produce the same network")
+	}
+	if equal(NewNetwork(1, 2), NewNetwork(2, 2)) {
+		t.Fatal("different seeds should produce different networks")
+	}
+}
+
+func TestNetwork_Snapshot(t *testing.T) {
+	a := NewNetwork(1, Detectors-DetectorDensity)
+	for i := range a.Neurons {
+		a.Neurons[i].AddConnection((i + 1) % len(a.Neurons))
+		a.Neurons[i].SetDetector(NewDetector(uint8(i+DetectorDensity), .5))
+	}
+	for i := 0; i < 1000; i++ {
+		a.Iterate(nil)
+	}
+	name := filepath.Join(t.TempDir(), "snapshot")
+	a.Snapshot().Write(name)
+	snapshot := ReadSnapshot(name)
+	for i := 0; i < 1000; i++ {
+		a.Iterate(nil)
+	}
+
+	b := NewNetwork(2, len(a.Neurons))
+	b.Restore(snapshot)
+	for i := 0; i < 1000; i++ {
+		b.Iterate(nil)
+	}
+	for i := range a.Neurons {
+		if a.Neurons[i].String() != b.Neurons[i].String() || a.Neurons[i].Complexity != b.Neurons[i].Complexity {
+			t.Fatalf("neuron %d differs after restore", i)
+		}
+		if a.Neurons[i].Tracker != nil && !reflect.DeepEqual(a.Neurons[i].Tracker.Particles, b.Neurons[i].Tracker.Particles) {
+			t.Fatalf("tracker of neuron %d differs after restore", i)
+		}
+	}
+}
+
+func TestNetwork_RunEvents(t *testing.T) {
+	type spike struct {
+		n int
+		t time.Duration
+	}
+	run := func() []spike {
+		network, spikes := NewNetwork(1, 3), []spike{}
+		for i := range network.Neurons {
+			network.Neurons[i].AddConnection((i + 1) % 3)
+		}
+		clocks := make([]event.Clock, 3)
+		for i := range clocks {
+			clocks[i] = event.Clock{
+				Period: time.Duration(10+i) * time.Microsecond,
+				Phase:  time.Duration(i) * time.Microsecond,
+				Jitter: time.Microsecond,
+			}
+		}
+		network.RunEvents(clocks, 5*time.Microsecond, 100*time.Millisecond, 1, func(n int, t time.Duration) {
+			spikes = append(spikes, spike{n, t})
+		})
+		return spikes
+	}
+	a, b := run(), run()
+	if len(a) == 0 {
+		t.Fatal("no spikes")
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("runs with the same seed should be identical")
+	}
+}
+
+// stateCoupling records the states it receives
+type stateCoupling struct {
+	states *[][]uint64
+}
+
+func (s stateCoupling) Couple(from, to *CA, rnd *rand.Rand) {
+	*s.states = append(*s.states, append([]uint64(nil), from.State...))
+}
+
+func TestNetwork_RunEventsDelay(t *testing.T) {
+	var sent, received [][]uint64
+	network := NewNetwork(1, 2)
+	network.Coupling = stateCoupling{&received}
+	network.Neurons[0].AddConnection(1)
+	network.Neurons[0].Detector = &fixedDetector{Fire: true}
+	network.Neurons[1].Detector = &fixedDetector{}
+	clocks := []event.Clock{{Period: time.Microsecond}, {Period: time.Microsecond}}
+	network.RunEvents(clocks, 10*time.Microsecond, 100*time.Microsecond, 1, func(n int, t time.Duration) {
+		sent = append(sent, append([]uint64(nil), network.Neurons[n].State...))
+	})
+	if len(received) == 0 || len(received) >= len(sent) {
+		t.Fatalf("%d messages were received out of %d", len(received), len(sent))
+	}
+	if !reflect.DeepEqual(received, sent[:len(received)]) {
+		t.Fatal("the delayed messages don't carry the state at the time of firing")
+	}
+}
+
+func TestNetwork_Clone(t *testing.T) {
+	a := NewNetwork(1, 4)
+	for i := range a.Neurons {
+		for j := range a.Neurons {
+			if i != j {
+				a.Neurons[i].AddConnection(j)
+			}
+		}
+	}
+	for i := range a.Neurons {
+		a.Neurons[i].Detector = &fixedDetector{Fire: i%2 == 0}
+	}
+	a.Target = &RoundRobinTarget{}
+	for i := 0; i < 101; i++ {
+		a.Iterate(nil)
+	}
+	b := a.Clone()
+	for i := 0; i < 1000; i++ {
+		a.Iterate(nil)
+	}
+	for i := 0; i < 1000; i++ {
+		b.Iterate(nil)
+	}
+	for i := range a.Neurons {
+		if a.Neurons[i].String() != b.Neurons[i].String() {
+			t.Fatalf("neuron %d of the clone differs", i)
+		}
+	}
+}
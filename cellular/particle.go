@@ -0,0 +1,171 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+const (
+	// Ether is the background pattern of rule 110 with a spatial period of 14 and a temporal period of 7
+	Ether = "11111000100110"
+	// EtherWidth is the width of the windows used to recognize the ether
+	EtherWidth = len(Ether)
+	// ParticleGap is the largest gap of ether cells inside of a particle
+	ParticleGap = 2 * EtherWidth
+	// MinParticleAge is the age at which a particle is no longer considered a flicker of the ether
+	MinParticleAge = 7
+)
+
+// EtherFilter recognizes the windows of cells that are part of the rule 110 ether
+type EtherFilter [1 << EtherWidth]bool
+
+// NewEtherFilter creates an ether filter from all of the phases of the ether
+func NewEtherFilter() *EtherFilter {
+	filter, ca := EtherFilter{}, CA{
+		Rule:  110,
+		Size:  EtherWidth,
+		State: make([]uint64, 1),
+		Next:  make([]uint64, 1),
+	}
+	for i, c := range Ether {
+		if c == '1' {
+			ca.State[0] |= 1 << uint(i)
+		}
+	}
+	for t := 0; t < 7; t++ {
+		state := ca.State[0]
+		for i := 0; i < EtherWidth; i++ {
+			filter[state] = true
+			state = state>>1 | (state&0x1)<<(EtherWidth-1)
+		}
+		ca.Step()
+	}
+	return &filter
+}
+
+// Background marks the cells of the cellular automaton that are covered by an ether window
+func (e *EtherFilter) Background(ca *CA, background []bool) {
+	size := ca.Size
+	for i := range background {
+		background[i] = false
+	}
+	window := 0
+	for j := 0; j < EtherWidth; j++ {
+		window |= int(ca.Bit(j%size)) << uint(j)
+	}
+	for i := 0; i < size; i++ {
+		if e[window] {
+			for j := 0; j < EtherWidth; j++ {
+				background[(i+j)%size] = true
+			}
+		}
+		window = window>>1 | int(ca.Bit((i+EtherWidth)%size))<<(EtherWidth-1)
+	}
+}
+
+// Particle is a localized structure moving through the ether
+type Particle struct {
+	ID          int
+	Start, Size int
+	Age         int
+}
+
+// Tracker detects particles in a rule 110 cellular automaton and tracks them across steps
+type Tracker struct {
+	Filter     *EtherFilter
+	Particles  []Particle
+	Collisions int
+	// Collided is the number of collisions during the last update
+	Collided   int
+	background []bool
+	id         int
+}
+
+// NewTracker creates a new particle tracker
+func NewTracker(filter *EtherFilter) *Tracker {
+	return &Tracker{
+		Filter: filter,
+	}
+}
+
+// Detect finds the particles as the runs of cells that are not ether,
+// runs separated by at most ParticleGap ether cells are merged and a lattice without ether is one particle
+func (t *Tracker) Detect(ca *CA) []Particle {
+	size := ca.Size
+	if len(t.background) != size {
+		t.background = make([]bool, size)
+	}
+	background := t.background
+	t.Filter.Background(ca, background)
+	start := 0
+	for start < size && !background[start] {
+		start++
+	}
+	if start == size {
+		return []Particle{{Start: 0, Size: size}}
+	}
+	var particles []Particle
+	for i := 1; i <= size; i++ {
+		cell := (start + i) % size
+		if background[cell] {
+			continue
+		}
+		if length := len(particles); length > 0 {
+			last := &particles[length-1]
+			if gap := (cell - last.Start - last.Size + size) % size; gap <= ParticleGap {
+				last.Size += gap + 1
+				continue
+			}
+		}
+		particles = append(particles, Particle{Start: cell, Size: 1})
+	}
+	if length := len(particles); length > 1 {
+		first, last := particles[0], &particles[length-1]
+		if gap := (first.Start - last.Start - last.Size + size) % size; gap <= ParticleGap {
+			last.Size += gap + first.Size
+			particles = particles[1:]
+		}
+	}
+	return particles
+}
+
+// overlaps checks if two particles overlap with a margin on a ring of size cells
+func overlaps(a, b Particle, margin, size int) bool {
+	d := (b.Start - a.Start + size) % size
+	if d < a.Size+margin {
+		return true
+	}
+	d = (a.Start - b.Start + size) % size
+	return d < b.Size+margin
+}
+
+// Update detects the particles after a step and matches them with the oldest overlapping previous particles,
+// a particle that overlaps more than one previous particle of at least MinParticleAge is counted as a collision
+func (t *Tracker) Update(ca *CA) {
+	particles := t.Detect(ca)
+	t.Collided = 0
+	for i := range particles {
+		matches, established, age := 0, 0, -1
+		for _, previous := range t.Particles {
+			if !overlaps(previous, particles[i], 2, ca.Size) {
+				continue
+			}
+			if previous.Age > age {
+				particles[i].ID, age = previous.ID, previous.Age
+			}
+			matches++
+			if previous.Age >= MinParticleAge {
+				established++
+			}
+		}
+		if established > 1 {
+			t.Collided++
+		}
+		if matches == 0 {
+			t.id++
+			particles[i].ID = t.id
+		} else {
+			particles[i].Age = age + 1
+		}
+	}
+	t.Particles, t.Collisions = particles, t.Collisions+t.Collided
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+)
+
+const (
+	paletteOff = iota
+	paletteSeparator
+	paletteFire
+	paletteNeurons
+	// paletteColors is the number of neuron colors that fit in a palette of 256 colors
+	paletteColors = 256 - paletteNeurons
+)
+
+// Row is one recorded step of a network of cellular automatons
+type Row struct {
+	States [][]uint64
+	Fired  []bool
+}
+
+// Renderer draws spacetime diagrams of a network of cellular automatons
+type Renderer struct {
+	// Colors are the colors of the on cells of each neuron, white is used for missing colors
+	Colors []color.Color
+	// Fire is the color of the off cells of a neuron that fired
+	Fire color.Color
+	// Separator is the width of the separator between neurons
+	Separator int
+	// Downsample records every Downsample steps
+	Downsample int
+	Sizes      []int
+	Rows       []Row
+	steps      int
+}
+
+// NewRenderer creates a new renderer with white cells and red firing events
+func NewRenderer() *Renderer {
+	return &Renderer{
+		Fire:       color.RGBA{0x80, 0, 0, 0xFF},
+		Separator:  3,
+		Downsample: 1,
+	}
+}
+
+// Record records the state of the network if the step is sampled
+func (r *Renderer) Record(network *Network) {
+	r.steps++
+	if r.Downsample > 1 && (r.steps-1)%r.Downsample != 0 {
+		return
+	}
+	neurons := network.Neurons
+	if r.Sizes == nil {
+		r.Sizes = make([]int, len(neurons))
+		for n := range neurons {
+			r.Sizes[n] = neurons[n].Size
+		}
+	}
+	row := Row{
+		States: make([][]uint64, len(neurons)),
+		Fired:  make([]bool, len(neurons)),
+	}
+	for n := range neurons {
+		row.States[n] = make([]uint64, len(neurons[n].State))
+		copy(row.States[n], neurons[n].State)
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// Fired marks a firing event of neuron n on the last recorded row
+func (r *Renderer) Fired(n int) {
+	if length := len(r.Rows); length > 0 {
+		r.Rows[length-1].Fired[n] = true
+	}
+}
+
+// Attach records the current state of the simulation and every following step and firing event
+func (r *Renderer) Attach(s *Simulator) {
+	r.Record(&s.Network)
+	s.OnFire(func(s *Simulator, n int) {
+		r.Fired(n)
+	})
+	s.OnStep(func(s *Simulator) {
+		r.Record(&s.Network)
+	})
+}
+
+// Palette returns the color palette of the diagram,
+// neurons past the first paletteColors reuse the colors of the earlier neurons
+func (r *Renderer) Palette() color.Palette {
+	palette := color.Palette{color.Black, color.Gray{0x80}, r.Fire}
+	for n := 0; n < len(r.Sizes) && n < paletteColors; n++ {
+		if n < len(r.Colors) && r.Colors[n] != nil {
+			palette = append(palette, r.Colors[n])
+		} else {
+			palette = append(palette, color.White)
+		}
+	}
+	return palette
+}
+
+// Width is the width of the diagram
+func (r *Renderer) Width() int {
+	width := 0
+	for _, size := range r.Sizes {
+		width += size
+	}
+	if len(r.Sizes) > 0 {
+		width += r.Separator * (len(r.Sizes) - 1)
+	}
+	return width
+}
+
+// Image draws the recorded rows from start to end
+func (r *Renderer) Image(start, end int) *image.Paletted {
+	width := r.Width()
+	img := image.NewPaletted(image.Rect(0, 0, width, end-start), r.Palette())
+	for y, row := range r.Rows[start:end] {
+		pix := img.Pix[y*img.Stride : y*img.Stride+width]
+		x := 0
+		for n, size := range r.Sizes {
+			if n > 0 {
+				for i := 0; i < r.Separator; i++ {
+					pix[x] = paletteSeparator
+					x++
+				}
+			}
+			off := uint8(paletteOff)
+			if row.Fired[n] {
+				off = paletteFire
+			}
+			state := row.States[n]
+			for i := 0; i < size; i++ {
+				if (state[i>>6]>>uint(i&0x3F))&0x1 == 0 {
+					pix[x] = off
+				} else {
+					pix[x] = uint8(paletteNeurons + n%paletteColors)
+				}
+				x++
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes the spacetime diagram to a png file
+func (r *Renderer) WritePNG(name string) {
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	err = png.Encode(out, r.Image(0, len(r.Rows)))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// WriteGIF writes the spacetime diagram to an animated gif file with height rows per frame
+func (r *Renderer) WriteGIF(name string, height int) {
+	if height <= 0 {
+		panic("frame height must be positive")
+	}
+	animation := gif.GIF{}
+	for start := 0; start < len(r.Rows); start += height {
+		end := start + height
+		if end > len(r.Rows) {
+			end = len(r.Rows)
+		}
+		animation.Image = append(animation.Image, r.Image(start, end))
+		animation.Delay = append(animation.Delay, 10)
+	}
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	err = gif.EncodeAll(out, &animation)
+	if err != nil {
+		panic(err)
+	}
+}
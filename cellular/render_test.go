@@ -0,0 +1,123 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderer_Image(t *testing.T) {
+	network := NewNetworkSizes(1, []int{10, 70, 5})
+	r := NewRenderer()
+	r.Separator = 2
+	r.Colors = []color.Color{color.RGBA{0xFF, 0, 0, 0xFF}}
+	r.Record(&network)
+	r.Fired(1)
+	network.Step()
+	r.Record(&network)
+	if width := r.Width(); width != 10+70+5+2*2 {
+		t.Fatalf("the width is %d not 89", width)
+	}
+	img := r.Image(0, len(r.Rows))
+	if bounds := img.Bounds(); bounds.Dx() != r.Width() || bounds.Dy() != 2 {
+		t.Fatalf("the image is %v", bounds)
+	}
+	if palette := img.Palette; len(palette) != paletteNeurons+3 || palette[paletteNeurons] != r.Colors[0] ||
+		palette[paletteNeurons+1] != color.White {
+		t.Fatalf("the palette is %v", palette)
+	}
+	for y, row := range r.Rows {
+		x := 0
+		for n, size := range r.Sizes {
+			if n > 0 {
+				for i := 0; i < r.Separator; i++ {
+					if index := img.ColorIndexAt(x, y); index != paletteSeparator {
+						t.Fatalf("row %d separator pixel %d is %d", y, x, index)
+					}
+					x++
+				}
+			}
+			off := uint8(paletteOff)
+			if y == 0 && n == 1 {
+				off = paletteFire
+			}
+			for i := 0; i < size; i++ {
+				expected := off
+				if (row.States[n][i>>6]>>uint(i&0x3F))&0x1 == 1 {
+					expected = uint8(paletteNeurons + n)
+				}
+				if index := img.ColorIndexAt(x, y); index != expected {
+					t.Fatalf("row %d neuron %d cell %d is %d not %d", y, n, i, index, expected)
+				}
+				x++
+			}
+		}
+	}
+}
+
+func TestRenderer_Downsample(t *testing.T) {
+	network := NewNetworkSizes(1, []int{CASize, 100})
+	r := NewRenderer()
+	r.Downsample = 3
+	var states [][]uint64
+	for i := 0; i < 7; i++ {
+		if i%3 == 0 {
+			states = append(states, append([]uint64(nil), network.Neurons[1].State...))
+		}
+		r.Record(&network)
+		network.Step()
+	}
+	if len(r.Rows) != len(states) {
+		t.Fatalf("%d rows were recorded not %d", len(r.Rows), len(states))
+	}
+	for i, row := range r.Rows {
+		for j, chunk := range row.States[1] {
+			if chunk != states[i][j] {
+				t.Fatalf("row %d chunk %d is not the state of step %d", i, j, 3*i)
+			}
+		}
+	}
+}
+
+func TestRenderer_Palette(t *testing.T) {
+	sizes := make([]int, 300)
+	for i := range sizes {
+		sizes[i] = 1
+	}
+	network := NewNetworkSizes(1, sizes)
+	for i := range network.Neurons {
+		network.Neurons[i].State[0] = 1
+	}
+	r := NewRenderer()
+	r.Separator = 0
+	r.Record(&network)
+	img := r.Image(0, 1)
+	if len(img.Palette) != 256 {
+		t.Fatalf("the palette has %d colors", len(img.Palette))
+	}
+	for n := range sizes {
+		if index, expected := img.ColorIndexAt(n, 0), uint8(paletteNeurons+n%paletteColors); index != expected {
+			t.Fatalf("neuron %d has color %d not %d", n, index, expected)
+		}
+	}
+}
+
+func TestRenderer_WriteGIF(t *testing.T) {
+	network := NewNetwork(1, 2)
+	r := NewRenderer()
+	for i := 0; i < 5; i++ {
+		r.Record(&network)
+		network.Step()
+	}
+	r.WriteGIF(filepath.Join(t.TempDir(), "diagram.gif"), 2)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("a frame height of zero didn't panic")
+		}
+	}()
+	r.WriteGIF(filepath.Join(t.TempDir(), "diagram.gif"), 0)
+}
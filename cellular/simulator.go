@@ -0,0 +1,77 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+// FireHook is called when a cellular automaton fires
+type FireHook func(s *Simulator, n int)
+
+// NoteHook is called when a cellular automaton with a note fires
+type NoteHook func(s *Simulator, n int, note uint8)
+
+// StepHook is called after each step of the network
+type StepHook func(s *Simulator)
+
+// Simulator runs a network of cellular automatons and notifies observers
+type Simulator struct {
+	Network   Network
+	Steps     int
+	Inputs    []Input
+	FireHooks []FireHook
+	NoteHooks []NoteHook
+	StepHooks []StepHook
+}
+
+// NewSimulator creates a new simulator for a network
+func NewSimulator(network Network) *Simulator {
+	return &Simulator{
+		Network: network,
+	}
+}
+
+// OnFire adds a hook that is called when a cellular automaton fires
+func (s *Simulator) OnFire(hook FireHook) {
+	s.FireHooks = append(s.FireHooks, hook)
+}
+
+// OnNote adds a hook that is called when a cellular automaton with a note fires
+func (s *Simulator) OnNote(hook NoteHook) {
+	s.NoteHooks = append(s.NoteHooks, hook)
+}
+
+// OnStep adds a hook that is called after each step
+func (s *Simulator) OnStep(hook StepHook) {
+	s.StepHooks = append(s.StepHooks, hook)
+}
+
+// AddInput adds an external stimulus that is injected before each step
+func (s *Simulator) AddInput(input Input) {
+	s.Inputs = append(s.Inputs, input)
+}
+
+// Step injects the inputs, fires the spiking cellular automatons and then steps the network
+func (s *Simulator) Step() {
+	s.Network.Drive(s.Inputs, s.Steps)
+	s.Network.Iterate(func(n int) {
+		for _, hook := range s.FireHooks {
+			hook(s, n)
+		}
+		if note := s.Network.Neurons[n].Note; note > 0 {
+			for _, hook := range s.NoteHooks {
+				hook(s, n, note)
+			}
+		}
+	})
+	s.Steps++
+	for _, hook := range s.StepHooks {
+		hook(s)
+	}
+}
+
+// Run steps the network the given number of times
+func (s *Simulator) Run(steps int) {
+	for i := 0; i < steps; i++ {
+		s.Step()
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"encoding/gob"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pointlander/sync/util"
+)
+
+// firing is a cellular automaton that fired at a step
+type firing struct {
+	n, step int
+}
+
+// iterate runs the network with the loop of Network.Iterate that the simulator replaces
+func iterate(network Network, inputs []Input, steps int) []firing {
+	var fired []firing
+	for step := 0; step < steps; step++ {
+		network.Drive(inputs, step)
+		network.Iterate(func(n int) {
+			fired = append(fired, firing{n, step})
+		})
+	}
+	return fired
+}
+
+// simulate runs the network with a simulator
+func simulate(t *testing.T, network Network, inputs []Input, steps int) []firing {
+	var fired []firing
+	simulator, notes, last := NewSimulator(network), 0, 0
+	for _, input := range inputs {
+		simulator.AddInput(input)
+	}
+	simulator.OnFire(func(s *Simulator, n int) {
+		fired = append(fired, firing{n, s.Steps})
+	})
+	simulator.OnNote(func(s *Simulator, n int, note uint8) {
+		if note == 0 || s.Network.Neurons[n].Note != note {
+			t.Fatalf("neuron %d has note %d not %d", n, s.Network.Neurons[n].Note, note)
+		}
+		if length := len(fired); length == 0 || fired[length-1] != (firing{n, s.Steps}) {
+			t.Fatalf("note of neuron %d at step %d without a spike", n, s.Steps)
+		}
+		notes++
+	})
+	simulator.OnStep(func(s *Simulator) {
+		if s.Steps != last+1 {
+			t.Fatalf("step hook called at step %d after step %d", s.Steps, last)
+		}
+		last = s.Steps
+	})
+	simulator.Run(steps)
+	if simulator.Steps != steps || last != steps {
+		t.Fatalf("simulated %d steps not %d", simulator.Steps, steps)
+	}
+	for _, f := range fired {
+		if simulator.Network.Neurons[f.n].Note > 0 {
+			notes--
+		}
+	}
+	if notes != 0 {
+		t.Fatalf("%d notes were not reported", notes)
+	}
+	return fired
+}
+
+func TestSimulator(t *testing.T) {
+	net := NetFactory(rand.New(rand.NewSource(1))).(*Net)
+	name := filepath.Join(t.TempDir(), "net")
+	out, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gob.NewEncoder(out).Encode(net)
+	out.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks := []struct {
+		name    string
+		network func() Network
+	}{
+		{"bench", benchNetwork},
+		{"fitness", func() Network { return net.NewNetwork(1) }},
+		{"inference", func() Network { return LoadNetwork(name) }},
+		{"inference default", func() Network { return LoadNetwork("") }},
+	}
+	inputs := []Input{{Neuron: 1, Chunk: 0, Series: []uint64{^uint64(0), 0, ^uint64(0)}}}
+	for _, n := range networks {
+		expected, fired := iterate(n.network(), nil, 5000), simulate(t, n.network(), nil, 5000)
+		if len(expected) == 0 {
+			t.Fatalf("%s: nothing fired", n.name)
+		}
+		if !reflect.DeepEqual(fired, expected) {
+			t.Fatalf("%s: the simulator fired differently than the network loop", n.name)
+		}
+		expected, fired = iterate(n.network(), inputs, 5000), simulate(t, n.network(), inputs, 5000)
+		if !reflect.DeepEqual(fired, expected) {
+			t.Fatalf("%s: the simulator fired differently than the network loop with inputs", n.name)
+		}
+	}
+}
+
+func TestNet_fitness(t *testing.T) {
+	net := NetFactory(rand.New(rand.NewSource(1))).(*Net)
+	network, markov := net.NewNetwork(1), util.Markov{}
+	for i := 0; i < 40000; i++ {
+		network.Iterate(func(n int) {
+			if note := network.Neurons[n].Note; note > 0 {
+				markov.Add(note)
+			}
+		})
+	}
+	if fitness, expected := net.fitness(1), markov.Entropy()/MaxMarkov; fitness != expected {
+		t.Fatalf("fitness is %f not %f", fitness, expected)
+	}
+}
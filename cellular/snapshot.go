@@ -0,0 +1,228 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"encoding/gob"
+	"math/rand"
+	"os"
+
+	"github.com/pointlander/sync/util"
+)
+
+// CASnapshot is the state of a cellular automaton
+type CASnapshot struct {
+	Rule                   uint8
+	Size                   int
+	State                  []uint64
+	Connections            []int
+	On                     uint64
+	Alpha                  float64
+	Low, Complexity, Spike float64
+	Threshold              float64
+	Note                   uint8
+	Detector               SpikeDetector
+	Tracker                *TrackerSnapshot
+	Absolute, Relative     int
+	Refractory, Recovery   int
+	Adaptation, Adapted    float64
+}
+
+func init() {
+	gob.Register(&ComplexityDetector{})
+	gob.Register(&DensityDetector{})
+	gob.Register(&EntropyDetector{})
+	gob.Register(&RefractoryDetector{})
+	gob.Register(&ParticleDetector{})
+}
+
+// TrackerSnapshot is the state of a particle tracker without its ether filter
+type TrackerSnapshot struct {
+	Particles            []Particle
+	Collisions, Collided int
+	ID                   int
+}
+
+// Snapshot captures the state of the particle tracker
+func (t *Tracker) Snapshot() *TrackerSnapshot {
+	particles := make([]Particle, len(t.Particles))
+	copy(particles, t.Particles)
+	return &TrackerSnapshot{
+		Particles:  particles,
+		Collisions: t.Collisions,
+		Collided:   t.Collided,
+		ID:         t.id,
+	}
+}
+
+// NewTracker creates a particle tracker from a snapshot
+func (s *TrackerSnapshot) NewTracker() *Tracker {
+	tracker := NewTracker(NewEtherFilter())
+	tracker.Particles = make([]Particle, len(s.Particles))
+	copy(tracker.Particles, s.Particles)
+	tracker.Collisions, tracker.Collided, tracker.id = s.Collisions, s.Collided, s.ID
+	return tracker
+}
+
+// Snapshot is a serializable snapshot of the state of a network of cellular automatons,
+// the coupling and target policies are not included
+type Snapshot struct {
+	Neurons []CASnapshot
+	Source  []byte
+	Steps   int
+}
+
+// Snapshot captures the state of the network
+func (network *Network) Snapshot() *Snapshot {
+	neurons := make([]CASnapshot, len(network.Neurons))
+	for i, ca := range network.Neurons {
+		state := make([]uint64, len(ca.State))
+		copy(state, ca.State)
+		connections := make([]int, len(ca.Connections))
+		copy(connections, ca.Connections)
+		neurons[i] = CASnapshot{
+			Rule:        ca.Rule,
+			Size:        ca.Size,
+			State:       state,
+			Connections: connections,
+			On:          ca.On,
+			Alpha:       ca.Alpha,
+			Low:         ca.Low,
+			Complexity:  ca.Complexity,
+			Spike:       ca.Spike,
+			Threshold:   ca.Threshold,
+			Note:        ca.Note,
+			Absolute:    ca.Absolute,
+			Relative:    ca.Relative,
+			Refractory:  ca.Refractory,
+			Recovery:    ca.Recovery,
+			Adaptation:  ca.Adaptation,
+			Adapted:     ca.Adapted,
+		}
+		if ca.Detector != nil {
+			neurons[i].Detector = ca.Detector.Clone()
+		}
+		if ca.Tracker != nil {
+			neurons[i].Tracker = ca.Tracker.Snapshot()
+		}
+	}
+	return &Snapshot{
+		Neurons: neurons,
+		Source:  network.Source.State(),
+	}
+}
+
+// Restore restores the state of the network from a snapshot,
+// couplings are kept for neurons with unchanged connections
+func (network *Network) Restore(snapshot *Snapshot) {
+	neurons := make([]CA, len(snapshot.Neurons))
+	for i, s := range snapshot.Neurons {
+		chunks := len(s.State)
+		ca := CA{
+			Rule:        s.Rule,
+			Size:        s.Size,
+			State:       make([]uint64, chunks),
+			Next:        make([]uint64, chunks),
+			Connections: make([]int, len(s.Connections)),
+			Couplings:   make([]Coupling, len(s.Connections)),
+			On:          s.On,
+			Alpha:       s.Alpha,
+			Low:         s.Low,
+			Complexity:  s.Complexity,
+			Spike:       s.Spike,
+			Threshold:   s.Threshold,
+			Note:        s.Note,
+			Absolute:    s.Absolute,
+			Relative:    s.Relative,
+			Refractory:  s.Refractory,
+			Recovery:    s.Recovery,
+			Adaptation:  s.Adaptation,
+			Adapted:     s.Adapted,
+		}
+		if s.Tracker != nil {
+			ca.Tracker = s.Tracker.NewTracker()
+		}
+		if s.Detector != nil {
+			ca.SetDetector(s.Detector.Clone())
+		}
+		copy(ca.State, s.State)
+		copy(ca.Connections, s.Connections)
+		if i < len(network.Neurons) && equal(network.Neurons[i].Connections, s.Connections) {
+			copy(ca.Couplings, network.Neurons[i].Couplings)
+		}
+		neurons[i] = ca
+	}
+	network.Neurons = neurons
+	network.Source.Restore(snapshot.Source)
+}
+
+// Snapshot captures the state of the simulation
+func (s *Simulator) Snapshot() *Snapshot {
+	snapshot := s.Network.Snapshot()
+	snapshot.Steps = s.Steps
+	return snapshot
+}
+
+// Restore restores the state of the simulation from a snapshot
+func (s *Simulator) Restore(snapshot *Snapshot) {
+	s.Network.Restore(snapshot)
+	s.Steps = snapshot.Steps
+}
+
+// Write writes the snapshot to a file
+func (s *Snapshot) Write(name string) {
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	encoder := gob.NewEncoder(out)
+	err = encoder.Encode(s)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReadSnapshot reads a snapshot from a file
+func ReadSnapshot(name string) *Snapshot {
+	snapshot := Snapshot{}
+	in, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	decoder := gob.NewDecoder(in)
+	err = decoder.Decode(&snapshot)
+	if err != nil {
+		panic(err)
+	}
+	return &snapshot
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone creates an independent copy of the network with the same coupling and a copy of the target
+func (network *Network) Clone() Network {
+	source := util.NewSource(0)
+	clone := Network{
+		Neurons:  network.Neurons,
+		Rnd:      rand.New(source),
+		Source:   source,
+		Coupling: network.Coupling,
+		Target:   network.Target.Clone(),
+	}
+	clone.Restore(network.Snapshot())
+	return clone
+}
@@ -0,0 +1,221 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math"
+)
+
+// SpikeDetector decides when a cellular automaton fires
+type SpikeDetector interface {
+	// Update is called after each step of the cellular automaton
+	Update(ca *CA)
+	// Test is called once per step to check if the cellular automaton is firing
+	Test(ca *CA) bool
+	// Clone creates a copy of the detector and its state
+	Clone() SpikeDetector
+}
+
+// Detector kinds for NewDetector
+const (
+	DetectorComplexity = iota
+	DetectorDensity
+	DetectorEntropy
+	DetectorRefractory
+	DetectorParticle
+	DetectorCollision
+	Detectors
+)
+
+// NewDetector creates a spike detector of a kind with a parameter in [0, 1],
+// the particle detectors have no parameter
+func NewDetector(kind uint8, parameter float64) SpikeDetector {
+	switch kind % Detectors {
+	case DetectorDensity:
+		return &DensityDetector{Level: parameter}
+	case DetectorEntropy:
+		return &EntropyDetector{Level: parameter}
+	case DetectorRefractory:
+		return &RefractoryDetector{
+			Detector: &ComplexityDetector{Factor: SpikeFactor},
+			Period:   int(MaxRefractory * parameter),
+		}
+	case DetectorParticle:
+		return &ParticleDetector{}
+	case DetectorCollision:
+		return &ParticleDetector{Collisions: true}
+	}
+	return &ComplexityDetector{Factor: 1 + 3*parameter}
+}
+
+// SetDetector sets the spike detector and adds a particle tracker if the detector reads one
+func (ca *CA) SetDetector(detector SpikeDetector) {
+	ca.Detector = detector
+	if _, ok := detector.(*ParticleDetector); ok && ca.Tracker == nil {
+		ca.Tracker = NewTracker(NewEtherFilter())
+	}
+}
+
+// ComplexityDetector fires when the spike computed from the complexity exceeds the effective threshold divided by Factor
+type ComplexityDetector struct {
+	Factor float64
+}
+
+// Update does nothing as the complexity is updated by the cellular automaton
+func (c *ComplexityDetector) Update(ca *CA) {}
+
+// Test checks if the spike exceeds the effective threshold divided by Factor
+func (c *ComplexityDetector) Test(ca *CA) bool {
+	return ca.Spike > ca.EffectiveThreshold()/c.Factor
+}
+
+// Clone creates a copy of the detector
+func (c *ComplexityDetector) Clone() SpikeDetector {
+	clone := *c
+	return &clone
+}
+
+// DensityDetector fires when the fraction of on cells crosses Level from below
+type DensityDetector struct {
+	Level    float64
+	Previous float64
+	Crossed  bool
+}
+
+// Update checks for a crossing of Level
+func (d *DensityDetector) Update(ca *CA) {
+	density := float64(ca.On) / float64(ca.Size)
+	d.Crossed, d.Previous = d.Previous < d.Level && density >= d.Level, density
+}
+
+// Test checks if the density crossed Level during the last step
+func (d *DensityDetector) Test(ca *CA) bool {
+	return d.Crossed
+}
+
+// Clone creates a copy of the detector
+func (d *DensityDetector) Clone() SpikeDetector {
+	clone := *d
+	return &clone
+}
+
+// EntropyDetector fires when the normalized entropy of the 4 bit blocks of the state falls below Level
+type EntropyDetector struct {
+	Level   float64
+	Entropy float64
+}
+
+// Update computes the entropy of the state
+func (e *EntropyDetector) Update(ca *CA) {
+	histogram, total := [16]float64{}, 0.0
+	for i := 0; i+4 <= ca.Size; i += 4 {
+		histogram[(ca.State[i>>6]>>uint(i&0x3F))&0xF]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+		p := count / total
+		entropy -= p * math.Log2(p)
+	}
+	e.Entropy = entropy / 4
+}
+
+// Test checks if the entropy is below Level
+func (e *EntropyDetector) Test(ca *CA) bool {
+	return e.Entropy < e.Level
+}
+
+// Clone creates a copy of the detector
+func (e *EntropyDetector) Clone() SpikeDetector {
+	clone := *e
+	return &clone
+}
+
+// Refractor is a spike detector with a refractory period that is started when the cellular automaton
+// fires and counted down when it rests
+type Refractor interface {
+	Spiked()
+	Rest()
+}
+
+// RefractoryDetector suppresses the firing of another detector for Period steps after each spike
+type RefractoryDetector struct {
+	Detector  SpikeDetector
+	Period    int
+	Remaining int
+}
+
+// Update updates the wrapped detector
+func (r *RefractoryDetector) Update(ca *CA) {
+	r.Detector.Update(ca)
+}
+
+// Test checks if the wrapped detector fires outside of the refractory period
+func (r *RefractoryDetector) Test(ca *CA) bool {
+	return r.Remaining == 0 && r.Detector.Test(ca)
+}
+
+// Spiked starts the refractory period
+func (r *RefractoryDetector) Spiked() {
+	r.Remaining = r.Period
+	if refractor, ok := r.Detector.(Refractor); ok {
+		refractor.Spiked()
+	}
+}
+
+// Rest counts down the refractory period
+func (r *RefractoryDetector) Rest() {
+	if r.Remaining > 0 {
+		r.Remaining--
+	}
+	if refractor, ok := r.Detector.(Refractor); ok {
+		refractor.Rest()
+	}
+}
+
+// Clone creates a copy of the detector and the wrapped detector
+func (r *RefractoryDetector) Clone() SpikeDetector {
+	clone := *r
+	clone.Detector = r.Detector.Clone()
+	return &clone
+}
+
+// ParticleDetector fires when the number of particles seen by the particle tracker of the
+// cellular automaton changes, or when particles collide if Collisions is set
+type ParticleDetector struct {
+	Collisions bool
+	Count      int
+	Fired      bool
+}
+
+// Update checks the particle tracker which is updated before the detector
+func (p *ParticleDetector) Update(ca *CA) {
+	tracker := ca.Tracker
+	if tracker == nil {
+		p.Fired = false
+		return
+	}
+	count := len(tracker.Particles)
+	if p.Collisions {
+		p.Fired = tracker.Collided > 0
+	} else {
+		p.Fired = count != p.Count
+	}
+	p.Count = count
+}
+
+// Test checks if the particles changed or collided during the last step
+func (p *ParticleDetector) Test(ca *CA) bool {
+	return p.Fired
+}
+
+// Clone creates a copy of the detector
+func (p *ParticleDetector) Clone() SpikeDetector {
+	clone := *p
+	return &clone
+}
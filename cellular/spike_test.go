@@ -0,0 +1,135 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParticleDetector(t *testing.T) {
+	particles := NewCA(110, 20*EtherWidth, SpikeThreshold, rand.New(rand.NewSource(1)))
+	collisions := NewCA(110, 20*EtherWidth, SpikeThreshold, rand.New(rand.NewSource(1)))
+	particles.SetDetector(NewDetector(DetectorParticle, 0))
+	collisions.SetDetector(NewDetector(DetectorCollision, 0))
+	if particles.Tracker == nil || collisions.Tracker == nil {
+		t.Fatal("particle detectors need a tracker")
+	}
+	count, changed, collided := 0, 0, 0
+	for i := 0; i < 2000; i++ {
+		particles.Step()
+		collisions.Step()
+		current := len(particles.Tracker.Particles)
+		if particles.Test() != (current != count) {
+			t.Fatalf("particle detector out of step with the tracker at step %d", i)
+		}
+		if collisions.Test() != (collisions.Tracker.Collided > 0) {
+			t.Fatalf("collision detector out of step with the tracker at step %d", i)
+		}
+		if current != count {
+			changed++
+		}
+		if collisions.Tracker.Collided > 0 {
+			collided++
+		}
+		count = current
+	}
+	if changed == 0 || collided == 0 {
+		t.Fatalf("changed=%d collided=%d", changed, collided)
+	}
+}
+
+// fixedDetector fires when Fire is set
+type fixedDetector struct {
+	Fire bool
+}
+
+func (f *fixedDetector) Update(ca *CA) {}
+
+func (f *fixedDetector) Test(ca *CA) bool {
+	return f.Fire
+}
+
+func (f *fixedDetector) Clone() SpikeDetector {
+	clone := *f
+	return &clone
+}
+
+func TestDensityDetector(t *testing.T) {
+	ca, detector := CA{Size: 100}, NewDetector(DetectorDensity, .5)
+	densities := [...]uint64{20, 60, 70, 30, 50, 50, 40, 80}
+	expected := [...]bool{false, true, false, false, true, false, false, true}
+	for i, on := range densities {
+		ca.On = on
+		detector.Update(&ca)
+		if detector.Test(&ca) != expected[i] {
+			t.Fatalf("density %d fired=%t", on, !expected[i])
+		}
+	}
+}
+
+func TestEntropyDetector(t *testing.T) {
+	ca, detector := NewCA(110, CASize, SpikeThreshold, rand.New(rand.NewSource(1))), NewDetector(DetectorEntropy, .5)
+	detector.Update(&ca)
+	if detector.Test(&ca) {
+		t.Fatalf("random state with entropy %f fired", detector.(*EntropyDetector).Entropy)
+	}
+	for i := range ca.State {
+		ca.State[i] = 0
+	}
+	detector.Update(&ca)
+	if !detector.Test(&ca) {
+		t.Fatalf("constant state with entropy %f didn't fire", detector.(*EntropyDetector).Entropy)
+	}
+}
+
+func TestRefractoryDetector(t *testing.T) {
+	ca := CA{}
+	detector := &RefractoryDetector{Detector: &fixedDetector{Fire: true}, Period: 3}
+	fired := make([]bool, 0, 9)
+	for i := 0; i < 9; i++ {
+		detector.Update(&ca)
+		test := detector.Test(&ca)
+		if detector.Test(&ca) != test {
+			t.Fatalf("step %d: testing changed the detector", i)
+		}
+		if test {
+			detector.Spiked()
+		} else {
+			detector.Rest()
+		}
+		fired = append(fired, test)
+	}
+	expected := [...]bool{true, false, false, false, true, false, false, false, true}
+	for i, f := range fired {
+		if f != expected[i] {
+			t.Fatalf("step %d fired=%t", i, f)
+		}
+	}
+}
+
+func TestComplexityDetector(t *testing.T) {
+	detector := NewDetector(DetectorComplexity, 1.0/3)
+	if factor := detector.(*ComplexityDetector).Factor; factor != SpikeFactor {
+		t.Fatalf("factor is %f", factor)
+	}
+	a := NewCA(110, CASize, SpikeThreshold, rand.New(rand.NewSource(1)))
+	b := NewCA(110, CASize, SpikeThreshold, rand.New(rand.NewSource(1)))
+	b.Detector = detector
+	fired := 0
+	for i := 0; i < 10000; i++ {
+		a.Step()
+		b.Step()
+		if a.Test() != b.Test() {
+			t.Fatalf("detector differs from the default rule at step %d", i)
+		}
+		if a.Test() {
+			fired++
+		}
+	}
+	if fired == 0 {
+		t.Fatal("never fired")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+// Target selects the cellular automatons that a firing cellular automaton sends messages to
+type Target interface {
+	Select(network *Network, n int) []int
+	// Clone returns an independent copy of the target
+	Clone() Target
+}
+
+// MaxComplexityTarget selects the connection with the maximum complexity
+type MaxComplexityTarget struct{}
+
+// Select selects the connection with the maximum complexity, or n if there is none
+func (MaxComplexityTarget) Select(network *Network, n int) []int {
+	m, max := n, 0.0
+	for _, c := range network.Neurons[n].Connections {
+		if complexity := network.Neurons[c].Complexity; complexity > max {
+			m, max = c, complexity
+		}
+	}
+	return []int{m}
+}
+
+// Clone returns the target which has no state
+func (t MaxComplexityTarget) Clone() Target {
+	return t
+}
+
+// MinComplexityTarget selects the connection with the minimum complexity
+type MinComplexityTarget struct{}
+
+// Select selects the connection with the minimum complexity
+func (MinComplexityTarget) Select(network *Network, n int) []int {
+	connections := network.Neurons[n].Connections
+	if len(connections) == 0 {
+		return nil
+	}
+	m := connections[0]
+	for _, c := range connections[1:] {
+		if network.Neurons[c].Complexity < network.Neurons[m].Complexity {
+			m = c
+		}
+	}
+	return []int{m}
+}
+
+// Clone returns the target which has no state
+func (t MinComplexityTarget) Clone() Target {
+	return t
+}
+
+// BroadcastTarget selects all of the connections
+type BroadcastTarget struct{}
+
+// Select selects all of the connections
+func (BroadcastTarget) Select(network *Network, n int) []int {
+	return network.Neurons[n].Connections
+}
+
+// Clone returns the target which has no state
+func (t BroadcastTarget) Clone() Target {
+	return t
+}
+
+// RandomTarget selects a random connection
+type RandomTarget struct{}
+
+// Select selects a random connection
+func (RandomTarget) Select(network *Network, n int) []int {
+	connections := network.Neurons[n].Connections
+	if len(connections) == 0 {
+		return nil
+	}
+	return []int{connections[network.Rnd.Intn(len(connections))]}
+}
+
+// Clone returns the target which has no state
+func (t RandomTarget) Clone() Target {
+	return t
+}
+
+// RoundRobinTarget selects each connection in turn
+type RoundRobinTarget struct {
+	Next []int
+}
+
+// Select selects the next connection in turn
+func (r *RoundRobinTarget) Select(network *Network, n int) []int {
+	connections := network.Neurons[n].Connections
+	if len(connections) == 0 {
+		return nil
+	}
+	for len(r.Next) <= n {
+		r.Next = append(r.Next, 0)
+	}
+	m := connections[r.Next[n]%len(connections)]
+	r.Next[n] = (r.Next[n] + 1) % len(connections)
+	return []int{m}
+}
+
+// Clone returns a copy of the target with its own turns
+func (r *RoundRobinTarget) Clone() Target {
+	next := make([]int, len(r.Next))
+	copy(next, r.Next)
+	return &RoundRobinTarget{Next: next}
+}
+
+// ProportionalTarget selects a connection with probability proportional to its complexity
+type ProportionalTarget struct{}
+
+// Select selects a connection with probability proportional to its complexity
+func (ProportionalTarget) Select(network *Network, n int) []int {
+	connections := network.Neurons[n].Connections
+	if len(connections) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, c := range connections {
+		sum += network.Neurons[c].Complexity
+	}
+	if sum == 0 {
+		return []int{connections[network.Rnd.Intn(len(connections))]}
+	}
+	r := network.Rnd.Float64() * sum
+	for _, c := range connections {
+		r -= network.Neurons[c].Complexity
+		if r < 0 {
+			return []int{c}
+		}
+	}
+	return []int{connections[len(connections)-1]}
+}
+
+// Clone returns the target which has no state
+func (t ProportionalTarget) Clone() Target {
+	return t
+}
@@ -0,0 +1,122 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cellular
+
+import (
+	"math"
+	"testing"
+)
+
+// newTargetNetwork creates a network where neuron 0 is connected to neurons 1 through 3 with the given complexities
+func newTargetNetwork(complexities ...float64) Network {
+	network := NewNetwork(1, 4)
+	for i, complexity := range complexities {
+		network.Neurons[0].AddConnection(i + 1)
+		network.Neurons[i+1].Complexity = complexity
+	}
+	return network
+}
+
+func TestTarget_NoConnections(t *testing.T) {
+	network := newTargetNetwork()
+	targets := [...]Target{
+		MinComplexityTarget{},
+		BroadcastTarget{},
+		RandomTarget{},
+		&RoundRobinTarget{},
+		ProportionalTarget{},
+	}
+	for _, target := range targets {
+		if selected := target.Select(&network, 0); len(selected) != 0 {
+			t.Errorf("%T selected %v without connections", target, selected)
+		}
+	}
+	if selected := (MaxComplexityTarget{}).Select(&network, 0); len(selected) != 1 || selected[0] != 0 {
+		t.Errorf("MaxComplexityTarget selected %v not the firing neuron without connections", selected)
+	}
+}
+
+func TestComplexityTargets(t *testing.T) {
+	network := newTargetNetwork(.3, .9, .1)
+	if selected := (MaxComplexityTarget{}).Select(&network, 0); len(selected) != 1 || selected[0] != 2 {
+		t.Errorf("MaxComplexityTarget selected %v not [2]", selected)
+	}
+	if selected := (MinComplexityTarget{}).Select(&network, 0); len(selected) != 1 || selected[0] != 3 {
+		t.Errorf("MinComplexityTarget selected %v not [3]", selected)
+	}
+
+	network = newTargetNetwork(.5, .5, .5)
+	if selected := (MaxComplexityTarget{}).Select(&network, 0); selected[0] != 1 {
+		t.Errorf("MaxComplexityTarget selected %v not the first of equal connections", selected)
+	}
+	if selected := (MinComplexityTarget{}).Select(&network, 0); selected[0] != 1 {
+		t.Errorf("MinComplexityTarget selected %v not the first of equal connections", selected)
+	}
+}
+
+func TestBroadcastTarget(t *testing.T) {
+	network := newTargetNetwork(.3, .9, .1)
+	selected := (BroadcastTarget{}).Select(&network, 0)
+	if len(selected) != 3 || selected[0] != 1 || selected[1] != 2 || selected[2] != 3 {
+		t.Errorf("BroadcastTarget selected %v not [1 2 3]", selected)
+	}
+}
+
+func TestRoundRobinTarget(t *testing.T) {
+	network := newTargetNetwork(.3, .9, .1)
+	network.Neurons[2].AddConnection(0)
+	network.Neurons[2].AddConnection(3)
+	target := &RoundRobinTarget{}
+	expected, other := [...]int{1, 2, 3, 1, 2, 3, 1}, [...]int{0, 3}
+	for i, e := range expected {
+		if selected := target.Select(&network, 0); len(selected) != 1 || selected[0] != e {
+			t.Fatalf("selection %d for neuron 0 is %v not [%d]", i, selected, e)
+		}
+		if selected := target.Select(&network, 2); len(selected) != 1 || selected[0] != other[i%2] {
+			t.Fatalf("selection %d for neuron 2 is %v not [%d]", i, selected, other[i%2])
+		}
+	}
+}
+
+func TestRandomTarget(t *testing.T) {
+	network := newTargetNetwork(.3, .9, .1)
+	counts := make([]int, 4)
+	for i := 0; i < 3000; i++ {
+		counts[(RandomTarget{}).Select(&network, 0)[0]]++
+	}
+	if counts[0] != 0 {
+		t.Fatalf("the firing neuron was selected %d times", counts[0])
+	}
+	for i, count := range counts[1:] {
+		if count < 800 || count > 1200 {
+			t.Errorf("connection %d was selected %d times out of 3000", i+1, count)
+		}
+	}
+}
+
+func TestProportionalTarget(t *testing.T) {
+	network := newTargetNetwork(0, 1, 3)
+	counts := make([]int, 4)
+	for i := 0; i < 4000; i++ {
+		counts[(ProportionalTarget{}).Select(&network, 0)[0]]++
+	}
+	if counts[0] != 0 || counts[1] != 0 {
+		t.Fatalf("connections without complexity were selected: %v", counts)
+	}
+	if ratio := float64(counts[3]) / float64(counts[2]); math.Abs(ratio-3) > .3 {
+		t.Errorf("the selection ratio is %f not 3: %v", ratio, counts)
+	}
+
+	network = newTargetNetwork(0, 0, 0)
+	counts = make([]int, 4)
+	for i := 0; i < 3000; i++ {
+		counts[(ProportionalTarget{}).Select(&network, 0)[0]]++
+	}
+	for i, count := range counts[1:] {
+		if count < 800 || count > 1200 {
+			t.Errorf("without complexity connection %d was selected %d times out of 3000", i+1, count)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+)
+
+// Kind is the kind of an event
+type Kind int
+
+const (
+	// Message events deliver a value to a node and happen before updates at the same time
+	Message Kind = iota
+	// Update events step a node
+	Update
+)
+
+// Clock is the update period, initial phase and random jitter of a node
+type Clock struct {
+	Period time.Duration
+	Phase  time.Duration
+	Jitter time.Duration
+}
+
+// Event is something that happens to a node at a simulated time
+type Event struct {
+	Time     time.Duration
+	Kind     Kind
+	Node     int
+	From     int
+	Value    int64
+	sequence uint64
+}
+
+// queue is a priority queue of events ordered by time, kind and then scheduling order
+type queue []Event
+
+func (q queue) Len() int {
+	return len(q)
+}
+
+func (q queue) Less(i, j int) bool {
+	a, b := &q[i], &q[j]
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.sequence < b.sequence
+}
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *queue) Push(x interface{}) {
+	*q = append(*q, x.(Event))
+}
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	length := len(old)
+	event := old[length-1]
+	*q = old[:length-1]
+	return event
+}
+
+// Scheduler is a deterministic discrete event scheduler for a network of nodes with their own clocks
+type Scheduler struct {
+	Now      time.Duration
+	Clocks   []Clock
+	Rnd      *rand.Rand
+	events   queue
+	sequence uint64
+}
+
+// NewScheduler creates a scheduler with the first update of each node at its phase,
+// the seed determines the jitter
+func NewScheduler(clocks []Clock, seed int64) *Scheduler {
+	s := &Scheduler{
+		Clocks: clocks,
+		Rnd:    rand.New(rand.NewSource(seed)),
+	}
+	for i, clock := range clocks {
+		s.Schedule(Event{
+			Time: clock.Phase,
+			Kind: Update,
+			Node: i,
+		})
+	}
+	return s
+}
+
+// Schedule adds an event to the queue
+func (s *Scheduler) Schedule(event Event) {
+	event.sequence = s.sequence
+	s.sequence++
+	heap.Push(&s.events, event)
+}
+
+// Send schedules the delivery of a message from one node to another after a delay
+func (s *Scheduler) Send(from, to int, delay time.Duration, value int64) {
+	s.Schedule(Event{
+		Time:  s.Now + delay,
+		Kind:  Message,
+		Node:  to,
+		From:  from,
+		Value: value,
+	})
+}
+
+// Next removes the next event that happens no later than until and advances the time,
+// the next update of a node is scheduled when its update is removed
+func (s *Scheduler) Next(until time.Duration) (Event, bool) {
+	if len(s.events) == 0 || s.events[0].Time > until {
+		return Event{}, false
+	}
+	event := heap.Pop(&s.events).(Event)
+	s.Now = event.Time
+	if event.Kind == Update {
+		clock := s.Clocks[event.Node]
+		period := clock.Period
+		if clock.Jitter > 0 {
+			period += time.Duration(s.Rnd.Int63n(int64(2*clock.Jitter))) - clock.Jitter
+		}
+		if period <= 0 {
+			period = 1
+		}
+		s.Schedule(Event{
+			Time: s.Now + period,
+			Kind: Update,
+			Node: event.Node,
+		})
+	}
+	return event, true
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_Next(t *testing.T) {
+	scheduler := NewScheduler([]Clock{
+		{Period: 3 * time.Microsecond},
+		{Period: 2 * time.Microsecond, Phase: time.Microsecond},
+	}, 1)
+	scheduler.Send(0, 1, time.Microsecond, 7)
+	expected := []Event{
+		{Time: 0, Kind: Update, Node: 0},
+		{Time: time.Microsecond, Kind: Message, Node: 1, Value: 7},
+		{Time: time.Microsecond, Kind: Update, Node: 1},
+		{Time: 3 * time.Microsecond, Kind: Update, Node: 0},
+		{Time: 3 * time.Microsecond, Kind: Update, Node: 1},
+		{Time: 5 * time.Microsecond, Kind: Update, Node: 1},
+	}
+	for i, e := range expected {
+		event, ok := scheduler.Next(5 * time.Microsecond)
+		if !ok {
+			t.Fatalf("missing event %d", i)
+		}
+		event.sequence = 0
+		if event != e {
+			t.Fatalf("event %d is %+v, expected %+v", i, event, e)
+		}
+	}
+	if _, ok := scheduler.Next(5 * time.Microsecond); ok {
+		t.Fatal("event past the end")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pointlander/sync/event"
+	"github.com/pointlander/sync/fixed"
+)
+
+// Sample is a state of a harmonic node recorded during an asynchronous run
+type Sample struct {
+	Node  int
+	Time  time.Duration
+	State fixed.Fixed
+	Fired bool
+}
+
+// Trace is the samples of each node of an asynchronous run
+type Trace [][]Sample
+
+// run steps a harmonic node at the rate of its clock starting after its phase until the context is canceled
+func (h *Harmonic) run(ctx context.Context, node int, clock event.Clock, rnd *rand.Rand, start time.Time, samples chan<- Sample) {
+	timer := time.NewTimer(clock.Phase)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		fired := h.Step()
+		sample := Sample{
+			Node:  node,
+			Time:  time.Since(start),
+			State: h.States[0],
+			Fired: fired,
+		}
+		select {
+		case samples <- sample:
+		case <-ctx.Done():
+			return
+		}
+		period := clock.Period
+		if clock.Jitter > 0 {
+			period += time.Duration(rnd.Int63n(int64(2*clock.Jitter))) - clock.Jitter
+		}
+		if period < 0 {
+			period = 0
+		}
+		timer.Reset(period)
+	}
+}
+
+// RunAsync runs each node of the harmonic network in its own goroutine with its own clock,
+// the nodes communicate over their channels and the coordinator collects the samples
+// until the context is canceled and all of the nodes have stopped, the network must use the channel backend
+func (h HarmonicNetwork) RunAsync(ctx context.Context, clocks []event.Clock, seed int64) Trace {
+	if h.Backend() != BackendChannel {
+		panic("asynchronous runs require the channel backend")
+	}
+	samples, trace := make(chan Sample, 1024), make(Trace, len(h))
+	start, wait := time.Now(), sync.WaitGroup{}
+	for i := range h {
+		for j := range h[i].Outbox {
+			h[i].Outbox[j].Done = ctx.Done()
+		}
+	}
+	for i := range h {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			h[i].run(ctx, i, clocks[i], rand.New(rand.NewSource(seed+int64(i))), start, samples)
+		}(i)
+	}
+	go func() {
+		wait.Wait()
+		close(samples)
+	}()
+	for sample := range samples {
+		trace[sample.Node] = append(trace[sample.Node], sample)
+	}
+	return trace
+}
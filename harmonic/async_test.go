@@ -0,0 +1,54 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pointlander/sync/event"
+)
+
+func TestHarmonicNetwork_RunAsync(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	network := genome.NewHarmonicNetwork()
+	clocks := make([]event.Clock, len(network))
+	for i := range clocks {
+		clocks[i] = event.Clock{Period: time.Millisecond, Jitter: 100 * time.Microsecond}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	trace := network.RunAsync(ctx, clocks, 1)
+	for i, samples := range trace {
+		if len(samples) == 0 {
+			t.Fatalf("node %d has no samples", i)
+		}
+	}
+}
+
+func TestHarmonicNetwork_RunEvents(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	clocks := make([]event.Clock, NetworkSize)
+	for i := range clocks {
+		clocks[i] = event.Clock{
+			Period: time.Duration(100+10*i) * time.Microsecond,
+			Phase:  time.Duration(i) * time.Microsecond,
+			Jitter: 10 * time.Microsecond,
+		}
+	}
+	a := genome.NewHarmonicNetwork().RunEvents(clocks, time.Microsecond, 100*time.Millisecond, 1)
+	b := genome.NewHarmonicNetwork().RunEvents(clocks, time.Microsecond, 100*time.Millisecond, 1)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("runs with the same seed should be identical")
+	}
+	for i, samples := range a {
+		if len(samples) == 0 {
+			t.Fatalf("node %d has no samples", i)
+		}
+	}
+}
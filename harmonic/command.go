@@ -5,11 +5,14 @@
 package harmonic
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/cmplx"
 	"math/rand"
+	"time"
 
+	"github.com/pointlander/sync/event"
 	"github.com/pointlander/sync/fixed"
 
 	"github.com/MaxHalford/eaopt"
@@ -218,3 +221,100 @@ func Inference(name string) {
 		}
 	}
 }
+
+// Analyze runs stability analysis on a harmonic network
+func Analyze(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadHarmonicGenome(name)
+	network := genome.NewHarmonicNetwork()
+	for i := range network {
+		if network[i].Oscillator != nil {
+			fmt.Printf("node %d: %T\n", i, network[i].Oscillator)
+			continue
+		}
+		stability := network[i].Stability(1e-3)
+		fmt.Printf("node %d: roots=%v radius=%f %s\n", i, stability.Roots, stability.Radius, stability.Class)
+	}
+	fmt.Printf("lyapunov=%f\n", network.Lyapunov(1e-3, 10, 1000))
+}
+
+// Async runs a harmonic network with a goroutine per node
+func Async(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadHarmonicGenome(name)
+	network := genome.NewHarmonicNetwork()
+	clocks := make([]event.Clock, len(network))
+	for i := range clocks {
+		clocks[i] = event.Clock{
+			Period: time.Duration(100+10*i) * time.Microsecond,
+			Jitter: 10 * time.Microsecond,
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	trace := network.RunAsync(ctx, clocks, 1)
+	for i, samples := range trace {
+		fired := 0
+		for _, sample := range samples {
+			if sample.Fired {
+				fired++
+			}
+		}
+		fmt.Printf("node %d: steps=%d fired=%d\n", i, len(samples), fired)
+	}
+}
+
+// Events runs a harmonic network with the discrete event scheduler
+func Events(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadHarmonicGenome(name)
+	network := genome.NewHarmonicNetwork()
+	clocks := make([]event.Clock, len(network))
+	for i := range clocks {
+		clocks[i] = event.Clock{
+			Period: time.Duration(100+10*i) * time.Microsecond,
+			Phase:  time.Duration(i) * time.Microsecond,
+			Jitter: 10 * time.Microsecond,
+		}
+	}
+	trace := network.RunEvents(clocks, time.Microsecond, time.Second, 1)
+	for i, samples := range trace {
+		fired := 0
+		for _, sample := range samples {
+			if sample.Fired {
+				fired++
+			}
+		}
+		fmt.Printf("node %d: steps=%d fired=%d\n", i, len(samples), fired)
+	}
+}
+
+// Compare compares pulse coupling against diffusive coupling with messages every interval steps
+func Compare(name string, interval int) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadHarmonicGenome(name)
+	for _, mode := range []Mode{ModePulse, ModeDiffusive} {
+		network := genome.NewHarmonicNetworkBackend(BackendSlice)
+		network.SetMode(mode, interval)
+		fired := make([]int, len(network))
+		for i := 0; i < Iterations; i++ {
+			for j := range network {
+				if network[j].Step() {
+					fired[j]++
+				}
+			}
+		}
+		fmt.Printf("%s: fired=%v\n", mode, fired)
+		network = genome.NewHarmonicNetworkBackend(BackendSlice)
+		network.SetMode(mode, interval)
+		fmt.Printf("%s: fitness=%f lyapunov=%f\n", mode, network.Fitness(), network.Lyapunov(1e-3, 10, 1000))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+// Policy is what a channel does when its delay line is full
+type Policy uint8
+
+const (
+	// PolicyDrop drops the new message
+	PolicyDrop Policy = iota
+	// PolicyOverwrite drops the oldest message to make room for the new message
+	PolicyOverwrite
+	// PolicyBlock blocks the sender until the receiver accepts the oldest message,
+	// a channel without Done can't block and drops the new message instead
+	PolicyBlock
+)
+
+// String returns the name of the policy
+func (p Policy) String() string {
+	switch p {
+	case PolicyDrop:
+		return "drop"
+	case PolicyOverwrite:
+		return "overwrite"
+	case PolicyBlock:
+		return "block"
+	}
+	return "unknown"
+}
+
+// DelayLine is a ring buffer of messages ordered by the time they are due
+type DelayLine struct {
+	Messages     []Message
+	Head, Length int
+	Time         uint64
+}
+
+// NewDelayLine creates a delay line that can hold capacity messages
+func NewDelayLine(capacity int) DelayLine {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return DelayLine{
+		Messages: make([]Message, capacity),
+	}
+}
+
+// Full is true if the delay line can't hold another message
+func (d *DelayLine) Full() bool {
+	return d.Length == len(d.Messages)
+}
+
+// Push adds a message to the end of the delay line, the delay line must not be full
+func (d *DelayLine) Push(message Message) {
+	d.Messages[(d.Head+d.Length)%len(d.Messages)] = message
+	d.Length++
+}
+
+// Peek returns the oldest message
+func (d *DelayLine) Peek() Message {
+	return d.Messages[d.Head]
+}
+
+// Pop removes the oldest message
+func (d *DelayLine) Pop() Message {
+	message := d.Messages[d.Head]
+	d.Head = (d.Head + 1) % len(d.Messages)
+	d.Length--
+	return message
+}
+
+// Due is true if the oldest message is due
+func (d *DelayLine) Due() bool {
+	return d.Length > 0 && d.Messages[d.Head].Time <= d.Time
+}
+
+// Pending returns the messages in the delay line from oldest to newest
+func (d *DelayLine) Pending() []Message {
+	messages := make([]Message, d.Length)
+	for i := range messages {
+		messages[i] = d.Messages[(d.Head+i)%len(d.Messages)]
+	}
+	return messages
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestChannel_Send(t *testing.T) {
+	out := make(ChanLink, 1024)
+	channel := NewChannel(0, 100, PolicyDrop, out)
+	for i := 0; i < 200; i++ {
+		channel.Step()
+		channel.Send(fixed.Fixed(i))
+	}
+	if len(out) != 100 || channel.Dropped != 0 {
+		t.Fatalf("expected 100 delivered and 0 dropped, got %d and %d", len(out), channel.Dropped)
+	}
+	for i := 0; i < 100; i++ {
+		if value := <-out; value != fixed.Fixed(i) {
+			t.Fatalf("expected %d, got %d", i, value)
+		}
+	}
+
+	for _, policy := range []Policy{PolicyDrop, PolicyOverwrite} {
+		out := make(ChanLink, 1)
+		channel := NewChannel(0, 2, policy, out)
+		for i := 0; i < 10; i++ {
+			channel.Step()
+			channel.Send(fixed.Fixed(i))
+		}
+		if channel.Dropped != 6 {
+			t.Fatalf("%s: expected 6 dropped, got %d", policy, channel.Dropped)
+		}
+		first, last := <-out, channel.Line.Pending()[channel.Line.Length-1].Value
+		if first != 0 {
+			t.Fatalf("%s: expected 0 delivered, got %d", policy, first)
+		}
+		if policy == PolicyDrop && last != 3 || policy == PolicyOverwrite && last != 9 {
+			t.Fatalf("%s: unexpected newest message %d", policy, last)
+		}
+	}
+
+	out, done := make(ChanLink, 1), make(chan struct{})
+	channel = NewChannel(0, 0, PolicyBlock, out)
+	channel.Done = done
+	go func() {
+		for i := 0; i < 11; i++ {
+			channel.Send(fixed.Fixed(i))
+		}
+		close(done)
+	}()
+	for i := 0; i < 10; i++ {
+		if value := <-out; value != fixed.Fixed(i) {
+			t.Fatalf("block: expected %d, got %d", i, value)
+		}
+	}
+	<-done
+	if channel.Dropped != 0 {
+		t.Fatalf("block: expected 0 dropped, got %d", channel.Dropped)
+	}
+}
+
+func TestChannel_SendBlockSynchronous(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	network := genome.NewHarmonicNetwork()
+	network.SetPolicy(PolicyBlock)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			network[0].Outbox[0].Send(Threshold)
+		}
+		network.Step(nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("a synchronous send with the block policy blocked")
+	}
+	if network[0].Outbox[0].Dropped == 0 {
+		t.Fatal("expected the messages that don't fit to be dropped")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"time"
+
+	"github.com/pointlander/sync/event"
+	"github.com/pointlander/sync/fixed"
+)
+
+// RunEvents runs the harmonic network with a discrete event scheduler until the simulated duration,
+// each node updates on its own clock and each message is scaled by its channel weight and arrives after its channel delay times delay,
+// messages bypass the channels so none are dropped
+func (h HarmonicNetwork) RunEvents(clocks []event.Clock, delay, duration time.Duration, seed int64) Trace {
+	scheduler, trace := event.NewScheduler(clocks, seed), make(Trace, len(h))
+	sums, gains, counts := make([]fixed.Fixed, len(h)), make([]fixed.Fixed, len(h)), make([]int, len(h))
+	for {
+		e, ok := scheduler.Next(duration)
+		if !ok {
+			break
+		}
+		node := &h[e.Node]
+		if e.Kind == event.Message {
+			sums[e.Node] += fixed.Fixed(e.Value)
+			gains[e.Node] += h[e.From].EdgeWeight(e.Node)
+			counts[e.Node]++
+			continue
+		}
+		fired := node.Update(sums[e.Node], gains[e.Node], counts[e.Node])
+		sums[e.Node], gains[e.Node], counts[e.Node] = 0, 0, 0
+		if value, ok := node.Emit(fired); ok {
+			for _, channel := range node.Outbox {
+				scheduler.Send(e.Node, channel.To, time.Duration(channel.Delay)*delay, int64(channel.Weight.Mul(value)))
+			}
+		}
+		trace[e.Node] = append(trace[e.Node], Sample{
+			Node:  e.Node,
+			Time:  e.Time,
+			State: node.States[0],
+			Fired: fired,
+		})
+	}
+	return trace
+}
@@ -17,19 +17,54 @@ import (
 	"github.com/mjibson/go-dsp/fft"
 )
 
-const Threshold = 8 * fixed.FixedOne
+const (
+	Threshold = 8 * fixed.FixedOne
+	// AdaptationShift sets the decay of adaptation per step to 1/2^AdaptationShift
+	AdaptationShift = 6
+	// MaxRefractory is the maximum random refractory period
+	MaxRefractory = 16
+)
+
+// Mode is how harmonic nodes are coupled
+type Mode uint8
+
+const (
+	// ModePulse sends a pulse when a node fires
+	ModePulse Mode = iota
+	// ModeDiffusive sends the state of a node every interval steps and couples by the edge weighted difference of states
+	ModeDiffusive
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModePulse:
+		return "pulse"
+	case ModeDiffusive:
+		return "diffusive"
+	}
+	return "unknown"
+}
 
 // Message is a message sent from one harmonic node to another harmonic node
 type Message struct {
-	Delay uint8
+	Time  uint64
 	Value fixed.Fixed
 }
 
 // Channel is an delayed output channel to another harmonic node
 type Channel struct {
-	Delay  uint8
-	Buffer [8]Message
-	Out    chan<- fixed.Fixed
+	To     int
+	Delay  int
+	Policy Policy
+	// Weight scales the messages, negative weights are inhibitory
+	Weight fixed.Fixed
+	Line   DelayLine
+	// Dropped is the number of messages dropped by the channel
+	Dropped uint64
+	Out     Link
+	// Done unblocks a blocked sender when closed
+	Done <-chan struct{}
 }
 
 // Harmonic is a harmonic node
@@ -37,56 +72,90 @@ type Harmonic struct {
 	Note    uint8
 	States  [2]fixed.Fixed
 	Weights [4]fixed.Fixed
-	Outbox  []Channel
-	Inbox   []<-chan fixed.Fixed
+	// Oscillator replaces the linear recurrence of the weights if set
+	Oscillator Oscillator
+	Outbox     []Channel
+	Inbox      []Link
+	// Gains are the weights of the channels of the inbox, a missing gain is one
+	Gains []fixed.Fixed
+	// Absolute and Relative are the absolute and relative refractory periods in steps
+	Absolute, Relative int
+	// Refractory and Recovery are the remaining steps of the refractory periods
+	Refractory, Recovery int
+	// Adaptation is added to the threshold on each spike and decays every step
+	Adaptation, Adapted fixed.Fixed
+	// Mode is the coupling mode and Interval is the number of steps between diffusive messages
+	Mode     Mode
+	Interval int
+	// Elapsed is the number of steps since the start
+	Elapsed int
 }
 
 // HarmonicGenome is a genome representing the parameters of a harmonic network
 type HarmonicGenome struct {
 	Connections slices.Uint8
+	Edges       slices.Fixed
 	States      slices.Fixed
 	Weights     slices.Fixed
+	Refractory  slices.Uint8
+	Adaptation  slices.Fixed
+	Types       slices.Uint8
+	Parameters  slices.Fixed
 }
 
 // HarmonicNetwork is a network of harmonic nodes
 type HarmonicNetwork []Harmonic
 
-// Send sends a delayed message to another harmonic node
+// NewChannel creates a channel with unit weight and a delay line long enough to never drop a message
+// if the receiver keeps up
+func NewChannel(to, delay int, policy Policy, out Link) Channel {
+	return Channel{
+		To:     to,
+		Delay:  delay,
+		Policy: policy,
+		Weight: fixed.FixedOne,
+		Line:   NewDelayLine(delay + 1),
+		Out:    out,
+	}
+}
+
+// Send sends a delayed message scaled by the weight of the channel to another harmonic node
 func (c *Channel) Send(value fixed.Fixed) {
-	if c.Delay == 0 {
-		select {
-		case c.Out <- value:
-		default:
+	value = c.Weight.Mul(value)
+	if c.Line.Full() {
+		switch c.Policy {
+		case PolicyDrop:
+			c.Dropped++
+			return
+		case PolicyOverwrite:
+			c.Line.Pop()
+			c.Dropped++
+		case PolicyBlock:
+			if !c.Out.Put(c.Line.Peek().Value, c.Done) {
+				c.Dropped++
+				return
+			}
+			c.Line.Pop()
 		}
-		return
 	}
-	for i, message := range c.Buffer {
-		if message.Delay != 0 {
-			continue
-		}
-		c.Buffer[i] = Message{
-			Delay: c.Delay,
-			Value: value,
-		}
-		return
+	c.Line.Push(Message{
+		Time:  c.Line.Time + uint64(c.Delay),
+		Value: value,
+	})
+	c.deliver()
+}
+
+// deliver sends the due messages until the receiver is full
+func (c *Channel) deliver() {
+	for c.Line.Due() && c.Out.Offer(c.Line.Peek().Value) {
+		c.Line.Pop()
 	}
 }
 
 // Step steps the state of the channel which can send messages
 func (c *Channel) Step() {
-	for i, message := range c.Buffer {
-		if message.Delay == 0 {
-			continue
-		}
-		message.Delay--
-		if message.Delay == 0 {
-			select {
-			case c.Out <- message.Value:
-			default:
-			}
-		}
-		c.Buffer[i] = message
-	}
+	c.Line.Time++
+	c.deliver()
 }
 
 // Step steps the state of the harmonic node
@@ -96,48 +165,133 @@ func (h *Harmonic) Step() bool {
 		outbox[i].Step()
 	}
 
-	sum, count := fixed.Fixed(0), 0
-	for _, input := range h.Inbox {
-		select {
-		case value := <-input:
+	sum, gain, count := fixed.Fixed(0), fixed.Fixed(0), 0
+	for i, input := range h.Inbox {
+		if value, ok := input.Poll(); ok {
 			sum += value
+			gain += h.Gain(i)
 			count++
-		default:
 		}
 	}
 
+	fired := h.Update(sum, gain, count)
+	if value, ok := h.Emit(fired); ok {
+		for i := range outbox {
+			outbox[i].Send(value)
+		}
+	}
+	return fired
+}
+
+// Gain is the weight of the channel of inbox i
+func (h *Harmonic) Gain(i int) fixed.Fixed {
+	if i < len(h.Gains) {
+		return h.Gains[i]
+	}
+	return fixed.FixedOne
+}
+
+// EdgeWeight is the weight of the channel to node to, or zero if there is none
+func (h *Harmonic) EdgeWeight(to int) fixed.Fixed {
+	for _, channel := range h.Outbox {
+		if channel.To == to {
+			return channel.Weight
+		}
+	}
+	return 0
+}
+
+// Emit returns the message to send after an update for the coupling mode, if any
+func (h *Harmonic) Emit(fired bool) (fixed.Fixed, bool) {
+	if h.Mode == ModeDiffusive {
+		elapsed := h.Elapsed
+		h.Elapsed++
+		if h.Interval > 1 && elapsed%h.Interval != 0 {
+			return 0, false
+		}
+		if h.Oscillator != nil {
+			return h.States[0] * OscillatorScale, true
+		}
+		return h.States[0], true
+	}
+	h.Elapsed++
+	return h.Pulse(), fired
+}
+
+// Update advances the oscillator with the sum, the sum of the channel weights and the count of the received messages
+// and checks if it fired, messages are in network units and are scaled to oscillator units for oscillator nodes,
+// in diffusive mode each message weighted by w contributes w times the difference of the states
+func (h *Harmonic) Update(sum, gain fixed.Fixed, count int) bool {
 	states, weights := h.States, h.Weights
-	states[1], states[0] = states[0], weights[0].Mul(states[0])+weights[1].Mul(states[1])
+	if h.Oscillator != nil {
+		states = h.Oscillator.Next(states)
+	} else {
+		states[1], states[0] = states[0], weights[0].Mul(states[0])+weights[1].Mul(states[1])
+	}
 	if count > 0 {
-		states[0] += weights[2].Mul(sum / fixed.Fixed(count))
+		input := sum / fixed.Fixed(count)
+		if h.Oscillator != nil {
+			input /= OscillatorScale
+		}
+		if h.Mode == ModeDiffusive {
+			input -= gain.Mul(h.States[0]) / fixed.Fixed(count)
+		}
+		states[0] += weights[2].Mul(input)
+	}
+	if h.Oscillator != nil {
+		states[0], states[1] = saturate(states[0]), saturate(states[1])
 	}
 	fired := false
-	if states[0].Abs() > weights[3].Abs() {
+	if h.Refractory == 0 && states[0].Abs() > h.EffectiveThreshold() {
 		fired = true
-		threshold := fixed.Fixed(Threshold)
-		if states[0] < 0 {
-			threshold = -threshold
-		}
-		for i := range outbox {
-			outbox[i].Send(threshold)
-		}
+		h.Refractory, h.Recovery = h.Absolute, h.Relative
+		h.Adapted += h.Adaptation
+	} else if h.Refractory > 0 {
+		h.Refractory--
+	} else if h.Recovery > 0 {
+		h.Recovery--
 	}
+	h.Adapted -= h.Adapted >> AdaptationShift
 	h.States = states
 	return fired
 }
 
-// NewHarmonicNetwork create a harmonic network for a harmonic genome
+// Pulse is the message sent by a firing harmonic node with the sign of its state
+func (h *Harmonic) Pulse() fixed.Fixed {
+	if h.States[0] < 0 {
+		return -Threshold
+	}
+	return Threshold
+}
+
+// EffectiveThreshold is the firing threshold raised by adaptation and the relative refractory period
+func (h *Harmonic) EffectiveThreshold() fixed.Fixed {
+	threshold := h.Weights[3].Abs()
+	if h.Relative > 0 {
+		threshold += threshold * fixed.Fixed(h.Recovery) / fixed.Fixed(h.Relative)
+	}
+	return threshold + h.Adapted
+}
+
+// NewHarmonicNetwork create a harmonic network for a harmonic genome that uses Go channels
 func (g *HarmonicGenome) NewHarmonicNetwork() HarmonicNetwork {
+	return g.NewHarmonicNetworkBackend(BackendChannel)
+}
+
+// NewHarmonicNetworkBackend create a harmonic network for a harmonic genome that uses a backend
+func (g *HarmonicGenome) NewHarmonicNetworkBackend(backend Backend) HarmonicNetwork {
 	network, c, s, w := make(HarmonicNetwork, NetworkSize), 0, 0, 0
 	for i := range network {
 		for j := range network {
 			if delay := g.Connections[c]; i != j && delay < 255 {
-				connection := make(chan fixed.Fixed, 8)
-				network[i].Outbox = append(network[i].Outbox, Channel{
-					Delay: delay,
-					Out:   connection,
-				})
+				connection := NewLink(backend)
+				channel := NewChannel(j, int(delay), PolicyDrop, connection)
+				if len(g.Edges) == len(g.Connections) {
+					channel.Weight = g.Edges[c]
+				}
+				network[i].Outbox = append(network[i].Outbox, channel)
 				network[j].Inbox = append(network[j].Inbox, connection)
+				network[j].Gains = append(network[j].Gains, channel.Weight)
 			}
 			c++
 		}
@@ -150,6 +304,24 @@ func (g *HarmonicGenome) NewHarmonicNetwork() HarmonicNetwork {
 			w++
 		}
 		network[i].Weights[3] = Threshold
+		if len(g.Refractory) == 2*len(network) {
+			network[i].Absolute = int(g.Refractory[2*i])
+			network[i].Relative = int(g.Refractory[2*i+1])
+		}
+		if len(g.Adaptation) == len(network) {
+			network[i].Adaptation = g.Adaptation[i]
+		}
+		if len(g.Types) == len(network) && len(g.Parameters) == Parameters*len(network) {
+			var parameters [Parameters]fixed.Fixed
+			copy(parameters[:], g.Parameters[Parameters*i:])
+			network[i].Oscillator = NewOscillator(g.Types[i], parameters)
+			if network[i].Oscillator != nil {
+				network[i].Weights[3] = OscillatorThreshold
+				for j := range network[i].States {
+					network[i].States[j] /= OscillatorScale
+				}
+			}
+		}
 	}
 	for i, note := range Notes {
 		network[i].Note = note
@@ -157,6 +329,46 @@ func (g *HarmonicGenome) NewHarmonicNetwork() HarmonicNetwork {
 	return network
 }
 
+// SetPolicy sets the policy of every channel in the harmonic network
+func (h HarmonicNetwork) SetPolicy(policy Policy) {
+	for i := range h {
+		for j := range h[i].Outbox {
+			h[i].Outbox[j].Policy = policy
+		}
+	}
+}
+
+// SetMode sets the coupling mode and the interval between diffusive messages of every node in the harmonic network
+func (h HarmonicNetwork) SetMode(mode Mode, interval int) {
+	for i := range h {
+		h[i].Mode, h[i].Interval = mode, interval
+	}
+}
+
+// Backend is the backend used by the links of the harmonic network
+func (h HarmonicNetwork) Backend() Backend {
+	for i := range h {
+		for _, channel := range h[i].Outbox {
+			if _, ok := channel.Out.(*Mailbox); ok {
+				return BackendSlice
+			}
+			return BackendChannel
+		}
+	}
+	return BackendChannel
+}
+
+// Dropped is the number of messages dropped by the channels of the harmonic network
+func (h HarmonicNetwork) Dropped() uint64 {
+	dropped := uint64(0)
+	for i := range h {
+		for _, channel := range h[i].Outbox {
+			dropped += channel.Dropped
+		}
+	}
+	return dropped
+}
+
 // Step steps the state of the harmonic network
 func (h HarmonicNetwork) Step(states [][]float64) (notes []uint8) {
 	var (
@@ -182,13 +394,18 @@ func (h HarmonicNetwork) Step(states [][]float64) (notes []uint8) {
 
 // Evaluate computes the fitness of the harmonic genome
 func (g *HarmonicGenome) Evaluate() (float64, error) {
-	network, markov := g.NewHarmonicNetwork(), util.Markov{}
-	data := make([][]float64, len(network))
+	return g.NewHarmonicNetworkBackend(BackendSlice).Fitness(), nil
+}
+
+// Fitness runs the harmonic network and computes how far the spectral entropy of the nodes is from the target
+func (h HarmonicNetwork) Fitness() float64 {
+	markov := util.Markov{}
+	data := make([][]float64, len(h))
 	for i := range data {
 		data[i] = make([]float64, 0, Iterations)
 	}
 	for i := 0; i < Iterations; i++ {
-		notes := network.Step(data)
+		notes := h.Step(data)
 		for _, note := range notes {
 			markov.Add(note)
 		}
@@ -198,37 +415,62 @@ func (g *HarmonicGenome) Evaluate() (float64, error) {
 		fit := Entropy(fft.FFTReal(values))/MaxSpectrumEntropy - .5
 		sum += fit * fit
 	}
-	fitness := sum / float64(len(network))
+	fitness := sum / float64(len(h))
 	//fitness := markov.Entropy()/MaxMarkov - .4
-	return fitness, nil
+	return fitness
 }
 
 // Mutate mutates the harmonic genome
 func (g *HarmonicGenome) Mutate(rng *rand.Rand) {
 	eaopt.MutPermute(g.Connections, 1, rng)
+	eaopt.MutPermute(g.Edges, 1, rng)
 	eaopt.MutPermute(g.States, 1, rng)
 	eaopt.MutPermute(g.Weights, 1, rng)
+	eaopt.MutPermute(g.Refractory, 1, rng)
+	eaopt.MutPermute(g.Adaptation, 1, rng)
+	eaopt.MutPermute(g.Types, 1, rng)
+	eaopt.MutPermute(g.Parameters, 1, rng)
 }
 
 // Crossover mates two harmonic genomes
 func (g *HarmonicGenome) Crossover(r eaopt.Genome, rng *rand.Rand) {
 	eaopt.CrossGNX(g.Connections, r.(*HarmonicGenome).Connections, 1, rng)
+	eaopt.CrossGNX(g.Edges, r.(*HarmonicGenome).Edges, 1, rng)
 	eaopt.CrossGNX(g.States, r.(*HarmonicGenome).States, 1, rng)
 	eaopt.CrossGNX(g.Weights, r.(*HarmonicGenome).Weights, 1, rng)
+	eaopt.CrossGNX(g.Refractory, r.(*HarmonicGenome).Refractory, 1, rng)
+	eaopt.CrossGNX(g.Adaptation, r.(*HarmonicGenome).Adaptation, 1, rng)
+	eaopt.CrossGNX(g.Types, r.(*HarmonicGenome).Types, 1, rng)
+	eaopt.CrossGNX(g.Parameters, r.(*HarmonicGenome).Parameters, 1, rng)
 }
 
 // Clone produces a copy of a harmonic genome
 func (g *HarmonicGenome) Clone() eaopt.Genome {
 	connections := make(slices.Uint8, len(g.Connections))
+	edges := make(slices.Fixed, len(g.Edges))
 	states := make(slices.Fixed, len(g.States))
 	weights := make(slices.Fixed, len(g.Weights))
+	refractory := make(slices.Uint8, len(g.Refractory))
+	adaptation := make(slices.Fixed, len(g.Adaptation))
+	types := make(slices.Uint8, len(g.Types))
+	parameters := make(slices.Fixed, len(g.Parameters))
 	copy(connections, g.Connections)
+	copy(edges, g.Edges)
 	copy(states, g.States)
 	copy(weights, g.Weights)
+	copy(refractory, g.Refractory)
+	copy(adaptation, g.Adaptation)
+	copy(types, g.Types)
+	copy(parameters, g.Parameters)
 	return &HarmonicGenome{
 		Connections: connections,
+		Edges:       edges,
 		States:      states,
 		Weights:     weights,
+		Refractory:  refractory,
+		Adaptation:  adaptation,
+		Types:       types,
+		Parameters:  parameters,
 	}
 }
 
@@ -274,6 +516,13 @@ func HarmonicGenomeFactory(rnd *rand.Rand) eaopt.Genome {
 			k++
 		}
 	}
+	edges := make(slices.Fixed, NetworkSize*NetworkSize)
+	for i := range edges {
+		edges[i] = fixed.Fixed(rnd.Intn(fixed.FixedOne))
+		if rnd.Intn(2) == 0 {
+			edges[i] = -edges[i]
+		}
+	}
 	states := make(slices.Fixed, 2*NetworkSize)
 	for i := range states {
 		states[i] = fixed.Fixed(rnd.Intn(8 << fixed.Places))
@@ -288,9 +537,30 @@ func HarmonicGenomeFactory(rnd *rand.Rand) eaopt.Genome {
 			weights[i] = -weights[i]
 		}
 	}
+	refractory := make(slices.Uint8, 2*NetworkSize)
+	for i := range refractory {
+		refractory[i] = uint8(rnd.Intn(MaxRefractory))
+	}
+	adaptation := make(slices.Fixed, NetworkSize)
+	for i := range adaptation {
+		adaptation[i] = fixed.Fixed(rnd.Intn(fixed.FixedOne))
+	}
+	types := make(slices.Uint8, NetworkSize)
+	for i := range types {
+		types[i] = uint8(rnd.Intn(NodeTypes))
+	}
+	parameters := make(slices.Fixed, Parameters*NetworkSize)
+	for i := range parameters {
+		parameters[i] = fixed.Fixed(rnd.Intn(2 * fixed.FixedOne))
+	}
 	return &HarmonicGenome{
 		Connections: connections,
+		Edges:       edges,
 		States:      states,
 		Weights:     weights,
+		Refractory:  refractory,
+		Adaptation:  adaptation,
+		Types:       types,
+		Parameters:  parameters,
 	}
 }
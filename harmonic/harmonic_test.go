@@ -0,0 +1,135 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestHarmonic_Refractory(t *testing.T) {
+	h := Harmonic{Absolute: 3}
+	h.Weights[0], h.Weights[3] = fixed.FixedOne, Threshold
+	h.States[0] = 2 * Threshold
+	fired := make([]bool, 0, 8)
+	for i := 0; i < 8; i++ {
+		fired = append(fired, h.Step())
+	}
+	expected := [...]bool{true, false, false, false, true, false, false, false}
+	for i, f := range fired {
+		if f != expected[i] {
+			t.Fatalf("step %d fired=%t expected %t", i, f, expected[i])
+		}
+	}
+}
+
+func TestHarmonicNetwork_Backend(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	a, b := genome.NewHarmonicNetworkBackend(BackendChannel), genome.NewHarmonicNetworkBackend(BackendSlice)
+	if a.Backend() != BackendChannel || b.Backend() != BackendSlice {
+		t.Fatal("wrong backend")
+	}
+	for i := 0; i < 10000; i++ {
+		a.Step(nil)
+		b.Step(nil)
+		for j := range a {
+			if a[j].States != b[j].States {
+				t.Fatalf("node %d differs at step %d", j, i)
+			}
+		}
+	}
+	if a.Dropped() != b.Dropped() {
+		t.Fatalf("dropped %d and %d messages", a.Dropped(), b.Dropped())
+	}
+}
+
+func benchmarkHarmonicNetwork(b *testing.B, backend Backend) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	network := genome.NewHarmonicNetworkBackend(backend)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		network.Step(nil)
+	}
+}
+
+func BenchmarkHarmonicNetwork_StepChannel(b *testing.B) {
+	benchmarkHarmonicNetwork(b, BackendChannel)
+}
+
+func BenchmarkHarmonicNetwork_StepSlice(b *testing.B) {
+	benchmarkHarmonicNetwork(b, BackendSlice)
+}
+
+func TestChannel_Weight(t *testing.T) {
+	out := &Mailbox{}
+	channel := NewChannel(0, 0, PolicyDrop, out)
+	channel.Weight = -fixed.FixedHalf
+	channel.Send(Threshold)
+	if value, ok := out.Poll(); !ok || value != -Threshold/2 {
+		t.Fatalf("expected %d, got %d", -Threshold/2, value)
+	}
+}
+
+func TestHarmonic_Diffusive(t *testing.T) {
+	weights := [4]fixed.Fixed{fixed.FixedFloat64(2 * math.Cos(.1)), -fixed.FixedOne, fixed.FixedFloat64(.05), Threshold}
+	network, out := make(HarmonicNetwork, 2), &Mailbox{}
+	network[0].Outbox = append(network[0].Outbox, NewChannel(1, 1, PolicyDrop, out))
+	network[1].Inbox = append(network[1].Inbox, out)
+	for i := range network {
+		network[i].Weights, network[i].States = weights, [2]fixed.Fixed{fixed.FixedOne, 0}
+	}
+	network.SetMode(ModeDiffusive, 1)
+	uncoupled := Harmonic{Weights: weights, States: [2]fixed.Fixed{fixed.FixedOne, 0}}
+	for i := 0; i < 1000; i++ {
+		network.Step(nil)
+		uncoupled.Step()
+		if network[0].States != uncoupled.States || network[1].States != uncoupled.States {
+			t.Fatalf("identical nodes should not be changed by diffusive coupling at step %d", i)
+		}
+	}
+
+	h := Harmonic{Mode: ModeDiffusive, Interval: 3}
+	for i := 0; i < 6; i++ {
+		if _, ok := h.Emit(false); ok != (i%3 == 0) {
+			t.Fatalf("step %d emitted=%t", i, ok)
+		}
+	}
+}
+
+func TestHarmonic_DiffusiveWeights(t *testing.T) {
+	run := func(weight, start fixed.Fixed) (fixed.Fixed, fixed.Fixed) {
+		network, out := make(HarmonicNetwork, 2), &Mailbox{}
+		channel := NewChannel(1, 1, PolicyDrop, out)
+		channel.Weight = weight
+		network[0].Outbox = append(network[0].Outbox, channel)
+		network[1].Inbox = append(network[1].Inbox, out)
+		network[1].Gains = append(network[1].Gains, weight)
+		for i := range network {
+			network[i].Weights = [4]fixed.Fixed{fixed.FixedOne, 0, fixed.FixedFloat64(.25), 1 << 30}
+		}
+		network[0].States = [2]fixed.Fixed{fixed.FixedOne, fixed.FixedOne}
+		network[1].States = [2]fixed.Fixed{start, start}
+		network.SetMode(ModeDiffusive, 1)
+		for i := 0; i < 100; i++ {
+			network.Step(nil)
+		}
+		return network[0].States[0], network[1].States[0]
+	}
+	for _, weight := range [...]fixed.Fixed{2 * fixed.FixedOne, -fixed.FixedHalf, fixed.FixedFloat64(.3)} {
+		if x0, x1 := run(weight, fixed.FixedOne); x0 != x1 {
+			t.Fatalf("weight %v: equal states drifted apart to %v and %v", weight, x0, x1)
+		}
+	}
+	if x0, x1 := run(2*fixed.FixedOne, 0); (x0 - x1).Abs() > fixed.FixedFloat64(.001) {
+		t.Fatalf("an excitatory edge didn't synchronize %v and %v", x0, x1)
+	}
+	start := fixed.FixedOne - fixed.FixedFloat64(.01)
+	if x0, x1 := run(-fixed.FixedHalf, start); (x0 - x1).Abs() <= fixed.FixedOne-start {
+		t.Fatalf("an inhibitory edge didn't push %v away from %v", x1, x0)
+	}
+}
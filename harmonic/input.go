@@ -0,0 +1,53 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+// Input adds a time series driving signal to the state of a harmonic node,
+// the signal is in network units and is scaled to oscillator units for oscillator nodes
+type Input struct {
+	Node   int
+	Series []fixed.Fixed
+}
+
+// Inject adds the signal for time t, nothing is added after the series ends
+func (i *Input) Inject(network HarmonicNetwork, t int) {
+	if t < 0 || t >= len(i.Series) {
+		return
+	}
+	node := &network[i.Node]
+	if node.Oscillator != nil {
+		node.States[0] = saturate(node.States[0] + i.Series[t]/OscillatorScale)
+		return
+	}
+	node.States[0] += i.Series[t]
+}
+
+// Drive adds the inputs for time t to the network
+func (h HarmonicNetwork) Drive(inputs []Input, t int) {
+	for i := range inputs {
+		inputs[i].Inject(h, t)
+	}
+}
+
+// StepInputs adds the inputs for time t to the network and then steps it
+func (h HarmonicNetwork) StepInputs(states [][]float64, inputs []Input, t int) (notes []uint8) {
+	h.Drive(inputs, t)
+	return h.Step(states)
+}
+
+// Sinusoid generates a sinusoidal driving signal with a period in steps
+func Sinusoid(amplitude, period float64, length int) []fixed.Fixed {
+	series := make([]fixed.Fixed, length)
+	for i := range series {
+		series[i] = fixed.FixedFloat64(amplitude * math.Sin(2*math.Pi*float64(i)/period))
+	}
+	return series
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestInput_Inject(t *testing.T) {
+	network := HarmonicNetwork{
+		{States: [2]fixed.Fixed{fixed.FixedHalf, 0}},
+		{States: [2]fixed.Fixed{fixed.FixedHalf, 0}},
+	}
+	input := Input{Node: 0, Series: []fixed.Fixed{fixed.FixedOne, fixed.FixedHalf}}
+	input.Inject(network, 0)
+	input.Inject(network, 1)
+	if expected := fixed.Fixed(2 * fixed.FixedOne); network[0].States[0] != expected {
+		t.Fatalf("the state is %v not %v", network[0].States[0], expected)
+	}
+	for _, step := range [...]int{-1, 2, 100} {
+		input.Inject(network, step)
+		if expected := fixed.Fixed(2 * fixed.FixedOne); network[0].States[0] != expected {
+			t.Fatalf("an input was added at step %d outside of the series: %v", step, network[0].States[0])
+		}
+	}
+	if network[1].States[0] != fixed.FixedHalf {
+		t.Fatalf("an input was added to the wrong node: %v", network[1].States[0])
+	}
+}
+
+func TestHarmonicNetwork_StepInputs(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	a, b, c := genome.NewHarmonicNetwork(), genome.NewHarmonicNetwork(), genome.NewHarmonicNetwork()
+	inputs := []Input{
+		{Node: 0, Series: Sinusoid(.5, 16, 100)},
+		{Node: 1, Series: Sinusoid(.5, 32, 100)},
+	}
+	differs := false
+	for i := 0; i < 200; i++ {
+		a.StepInputs(nil, inputs, i)
+		// nothing is injected after the series ends so b is only driven while the series lasts
+		if i < 100 {
+			b.Drive(inputs, i)
+		}
+		b.Step(nil)
+		c.Step(nil)
+		for j := range a {
+			if a[j].States != b[j].States {
+				t.Fatalf("step %d node %d: StepInputs differs from Drive and Step", i, j)
+			}
+			differs = differs || a[j].States != c[j].States
+		}
+	}
+	if !differs {
+		t.Fatal("the inputs had no effect")
+	}
+}
+
+func TestInput_InjectOscillator(t *testing.T) {
+	network := HarmonicNetwork{
+		{Oscillator: NewOscillator(NodeDamped, [Parameters]fixed.Fixed{fixed.FixedOne, fixed.FixedHalf})},
+	}
+	input := Input{Node: 0, Series: []fixed.Fixed{Threshold, 1000 * Threshold}}
+	input.Inject(network, 0)
+	if network[0].States[0] != OscillatorThreshold {
+		t.Fatalf("the oscillator state is %v not %v", network[0].States[0], OscillatorThreshold)
+	}
+	input.Inject(network, 1)
+	if network[0].States[0] != Saturation {
+		t.Fatalf("the oscillator state is %v not saturated", network[0].States[0])
+	}
+}
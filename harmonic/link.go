@@ -0,0 +1,135 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"github.com/pointlander/sync/fixed"
+)
+
+// LinkCapacity is the number of messages a link can hold
+const LinkCapacity = 8
+
+// Backend is how messages are carried from a channel to the inbox of a harmonic node
+type Backend uint8
+
+const (
+	// BackendChannel carries messages over Go channels and supports asynchronous runs
+	BackendChannel Backend = iota
+	// BackendSlice carries messages in plain ring buffers for fast synchronous runs
+	BackendSlice
+)
+
+// String returns the name of the backend
+func (b Backend) String() string {
+	switch b {
+	case BackendChannel:
+		return "channel"
+	case BackendSlice:
+		return "slice"
+	}
+	return "unknown"
+}
+
+// Link carries messages from a channel to the inbox of a harmonic node
+type Link interface {
+	// Offer adds a message if there is room
+	Offer(value fixed.Fixed) bool
+	// Put adds a message waiting for room until done is closed
+	Put(value fixed.Fixed, done <-chan struct{}) bool
+	// Poll removes a message if there is one
+	Poll() (fixed.Fixed, bool)
+	// Len is the number of messages in the link
+	Len() int
+}
+
+// NewLink creates a link for a backend
+func NewLink(backend Backend) Link {
+	switch backend {
+	case BackendChannel:
+		return make(ChanLink, LinkCapacity)
+	case BackendSlice:
+		return &Mailbox{}
+	}
+	panic("unknown backend")
+}
+
+// ChanLink is a link backed by a Go channel
+type ChanLink chan fixed.Fixed
+
+// Offer adds a message if there is room
+func (c ChanLink) Offer(value fixed.Fixed) bool {
+	select {
+	case c <- value:
+		return true
+	default:
+		return false
+	}
+}
+
+// Put adds a message waiting for room until done is closed, without done it doesn't wait
+// as the sender is then the goroutine that reads the link in a synchronous run
+func (c ChanLink) Put(value fixed.Fixed, done <-chan struct{}) bool {
+	if done == nil {
+		return c.Offer(value)
+	}
+	select {
+	case c <- value:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// Poll removes a message if there is one
+func (c ChanLink) Poll() (fixed.Fixed, bool) {
+	select {
+	case value := <-c:
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+// Len is the number of messages in the link
+func (c ChanLink) Len() int {
+	return len(c)
+}
+
+// Mailbox is a link backed by a ring buffer for use by a single goroutine
+type Mailbox struct {
+	Values       [LinkCapacity]fixed.Fixed
+	Head, Length int
+}
+
+// Offer adds a message if there is room
+func (m *Mailbox) Offer(value fixed.Fixed) bool {
+	if m.Length == LinkCapacity {
+		return false
+	}
+	m.Values[(m.Head+m.Length)%LinkCapacity] = value
+	m.Length++
+	return true
+}
+
+// Put adds a message if there is room, a mailbox has a single goroutine so it can't wait
+func (m *Mailbox) Put(value fixed.Fixed, done <-chan struct{}) bool {
+	return m.Offer(value)
+}
+
+// Poll removes a message if there is one
+func (m *Mailbox) Poll() (fixed.Fixed, bool) {
+	if m.Length == 0 {
+		return 0, false
+	}
+	value := m.Values[m.Head]
+	m.Head = (m.Head + 1) % LinkCapacity
+	m.Length--
+	return value, true
+}
+
+// Len is the number of messages in the link
+func (m *Mailbox) Len() int {
+	return m.Length
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"encoding/gob"
+	"math"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+const (
+	// NodeLinear is the linear two term recurrence of the harmonic node weights
+	NodeLinear = iota
+	// NodeDamped is a damped harmonic oscillator
+	NodeDamped
+	// NodeVanDerPol is a Van der Pol oscillator
+	NodeVanDerPol
+	// NodeDuffing is a driven Duffing oscillator
+	NodeDuffing
+	// NodeStuartLandau is a Stuart-Landau oscillator
+	NodeStuartLandau
+	// NodeTypes is the number of node types
+	NodeTypes
+)
+
+const (
+	// Dt is the integration time step of the oscillators
+	Dt fixed.Fixed = fixed.FixedOne >> 4
+	// OscillatorThreshold is the firing threshold of the oscillators which have unit scale
+	OscillatorThreshold = fixed.FixedOne
+	// OscillatorScale converts network units to oscillator units
+	OscillatorScale = Threshold / OscillatorThreshold
+	// Saturation bounds the states of the oscillators so the fixed point arithmetic can't overflow
+	Saturation = 4 * fixed.FixedOne
+	// Parameters is the number of parameters of an oscillator
+	Parameters = 4
+	// DuffingFrequency is the angular frequency of the Duffing drive
+	DuffingFrequency = 1.2
+)
+
+func init() {
+	gob.Register(&DampedOscillator{})
+	gob.Register(&VanDerPol{})
+	gob.Register(&Duffing{})
+	gob.Register(&StuartLandau{})
+}
+
+// Oscillator computes the next state of a harmonic node, the coupling is added by the node
+type Oscillator interface {
+	// Next computes the next state from the current state
+	Next(states [2]fixed.Fixed) [2]fixed.Fixed
+	// Clone copies the oscillator including its internal state
+	Clone() Oscillator
+}
+
+// NewOscillator creates an oscillator of a node type from its parameters, nil is the linear recurrence
+func NewOscillator(kind uint8, parameters [Parameters]fixed.Fixed) Oscillator {
+	switch kind {
+	case NodeLinear:
+		return nil
+	case NodeDamped:
+		return &DampedOscillator{
+			Omega: parameters[0],
+			Gamma: parameters[1],
+		}
+	case NodeVanDerPol:
+		return &VanDerPol{
+			Mu:    parameters[0],
+			Omega: parameters[1],
+		}
+	case NodeDuffing:
+		return NewDuffing(parameters[0], parameters[1], parameters[2], parameters[3])
+	case NodeStuartLandau:
+		return &StuartLandau{
+			Mu:    parameters[0],
+			Omega: parameters[1],
+			Beta:  parameters[2],
+		}
+	}
+	panic("unknown node type")
+}
+
+// saturate clamps a state of an oscillator to the saturation bounds
+func saturate(state fixed.Fixed) fixed.Fixed {
+	if state > Saturation {
+		return Saturation
+	} else if state < -Saturation {
+		return -Saturation
+	}
+	return state
+}
+
+// verlet integrates an acceleration with states being the current and previous position
+func verlet(states [2]fixed.Fixed, acceleration func(x, v fixed.Fixed) fixed.Fixed) [2]fixed.Fixed {
+	x, v := states[0], (states[0]-states[1])*(fixed.FixedOne/Dt)
+	a := acceleration(x, v)
+	return [2]fixed.Fixed{2*x - states[1] + Dt.Mul(Dt.Mul(a)), x}
+}
+
+// DampedOscillator is a damped harmonic oscillator x” = -omega^2 x - 2 gamma x'
+type DampedOscillator struct {
+	Omega, Gamma fixed.Fixed
+}
+
+// Next computes the next state from the current state
+func (d *DampedOscillator) Next(states [2]fixed.Fixed) [2]fixed.Fixed {
+	return verlet(states, func(x, v fixed.Fixed) fixed.Fixed {
+		return -d.Omega.Mul(d.Omega.Mul(x)) - 2*d.Gamma.Mul(v)
+	})
+}
+
+// Clone copies the oscillator
+func (d *DampedOscillator) Clone() Oscillator {
+	clone := *d
+	return &clone
+}
+
+// VanDerPol is a Van der Pol oscillator x” = mu (1 - x^2) x' - omega^2 x
+type VanDerPol struct {
+	Mu, Omega fixed.Fixed
+}
+
+// Next computes the next state from the current state
+func (p *VanDerPol) Next(states [2]fixed.Fixed) [2]fixed.Fixed {
+	return verlet(states, func(x, v fixed.Fixed) fixed.Fixed {
+		return p.Mu.Mul((fixed.FixedOne - x.Mul(x)).Mul(v)) - p.Omega.Mul(p.Omega.Mul(x))
+	})
+}
+
+// Clone copies the oscillator
+func (p *VanDerPol) Clone() Oscillator {
+	clone := *p
+	return &clone
+}
+
+// Duffing is a driven Duffing oscillator x” = -delta x' - alpha x - beta x^3 + force cos(DuffingFrequency t),
+// the drive is a fixed point linear recurrence
+type Duffing struct {
+	Delta, Alpha, Beta, Force fixed.Fixed
+	Coefficient               fixed.Fixed
+	Drive                     [2]fixed.Fixed
+}
+
+// NewDuffing creates a driven Duffing oscillator
+func NewDuffing(delta, alpha, beta, force fixed.Fixed) *Duffing {
+	step := DuffingFrequency * Dt.Float64()
+	return &Duffing{
+		Delta:       delta,
+		Alpha:       alpha,
+		Beta:        beta,
+		Force:       force,
+		Coefficient: fixed.FixedFloat64(2 * math.Cos(step)),
+		Drive:       [2]fixed.Fixed{fixed.FixedOne, fixed.FixedFloat64(math.Cos(step))},
+	}
+}
+
+// Next computes the next state from the current state and advances the drive
+func (d *Duffing) Next(states [2]fixed.Fixed) [2]fixed.Fixed {
+	drive := d.Drive[0]
+	d.Drive[1], d.Drive[0] = d.Drive[0], d.Coefficient.Mul(d.Drive[0])-d.Drive[1]
+	return verlet(states, func(x, v fixed.Fixed) fixed.Fixed {
+		return -d.Delta.Mul(v) - d.Alpha.Mul(x) - d.Beta.Mul(x.Mul(x.Mul(x))) + d.Force.Mul(drive)
+	})
+}
+
+// Clone copies the oscillator including the state of the drive
+func (d *Duffing) Clone() Oscillator {
+	clone := *d
+	return &clone
+}
+
+// StuartLandau is a Stuart-Landau oscillator z' = (mu + i omega) z - (1 + i beta) |z|^2 z
+// with states being the real and imaginary parts of z
+type StuartLandau struct {
+	Mu, Omega, Beta fixed.Fixed
+}
+
+// Next computes the next state from the current state
+func (s *StuartLandau) Next(states [2]fixed.Fixed) [2]fixed.Fixed {
+	x, y := states[0], states[1]
+	r := x.Mul(x) + y.Mul(y)
+	dx := s.Mu.Mul(x) - s.Omega.Mul(y) - r.Mul(x-s.Beta.Mul(y))
+	dy := s.Omega.Mul(x) + s.Mu.Mul(y) - r.Mul(s.Beta.Mul(x)+y)
+	return [2]fixed.Fixed{x + Dt.Mul(dx), y + Dt.Mul(dy)}
+}
+
+// Clone copies the oscillator
+func (s *StuartLandau) Clone() Oscillator {
+	clone := *s
+	return &clone
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestOscillator(t *testing.T) {
+	amplitude := func(oscillator Oscillator, states [2]fixed.Fixed) float64 {
+		max := 0.0
+		for i := 0; i < 20000; i++ {
+			states = oscillator.Next(states)
+			if i > 10000 {
+				max = math.Max(max, math.Abs(states[0].Float64()))
+			}
+		}
+		return max
+	}
+	start := [2]fixed.Fixed{fixed.FixedHalf, fixed.FixedHalf}
+	if a := amplitude(NewOscillator(NodeDamped, [Parameters]fixed.Fixed{fixed.FixedOne, fixed.FixedHalf}), start); a > .01 {
+		t.Fatalf("damped oscillator amplitude %f", a)
+	}
+	if a := amplitude(NewOscillator(NodeVanDerPol, [Parameters]fixed.Fixed{fixed.FixedOne, fixed.FixedOne}), start); math.Abs(a-2) > .1 {
+		t.Fatalf("van der pol oscillator amplitude %f", a)
+	}
+	if a := amplitude(NewOscillator(NodeStuartLandau, [Parameters]fixed.Fixed{4 * fixed.FixedOne, fixed.FixedOne}), start); math.Abs(a-2) > .1 {
+		t.Fatalf("stuart-landau oscillator amplitude %f", a)
+	}
+	duffing := NewOscillator(NodeDuffing, [Parameters]fixed.Fixed{
+		fixed.FixedFloat64(.3), -fixed.FixedOne, fixed.FixedOne, fixed.FixedHalf,
+	})
+	if a := amplitude(duffing, start); a < .5 || a > 3 {
+		t.Fatalf("duffing oscillator amplitude %f", a)
+	}
+}
+
+func TestHarmonicNetwork_Oscillators(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	for i := range genome.Types {
+		genome.Types[i] = uint8(i % NodeTypes)
+	}
+	a := genome.NewHarmonicNetworkBackend(BackendSlice)
+	for i := 0; i < 1000; i++ {
+		a.Step(nil)
+	}
+	name := filepath.Join(t.TempDir(), "snapshot.bin")
+	a.Snapshot().Write(name)
+	b := ReadSnapshot(name).NewHarmonicNetwork()
+	for i := 0; i < 1000; i++ {
+		a.Step(nil)
+		b.Step(nil)
+		for j := range a {
+			if a[j].States != b[j].States {
+				t.Fatalf("node %d differs at step %d", j, i)
+			}
+		}
+	}
+}
+
+func TestHarmonicNetwork_OscillatorsBounded(t *testing.T) {
+	saturated, total := 0, 0
+	for seed := int64(1); seed <= 20; seed++ {
+		genome := HarmonicGenomeFactory(rand.New(rand.NewSource(seed))).(*HarmonicGenome)
+		for i := range genome.Types {
+			genome.Types[i] = uint8((i + int(seed)) % NodeTypes)
+		}
+		network := genome.NewHarmonicNetworkBackend(BackendSlice)
+		for i := 0; i < Iterations; i++ {
+			network.Step(nil)
+			for j := range network {
+				if network[j].Oscillator == nil {
+					continue
+				}
+				for _, state := range network[j].States {
+					if state.Abs() > Saturation {
+						t.Fatalf("seed %d node %d state %s out of bounds at step %d", seed, j, state, i)
+					}
+				}
+				total++
+				if network[j].States[0].Abs() == Saturation {
+					saturated++
+				}
+			}
+		}
+	}
+	if 2*saturated > total {
+		t.Fatalf("%d of %d states are saturated", saturated, total)
+	}
+}
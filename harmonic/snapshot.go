@@ -0,0 +1,159 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"encoding/gob"
+	"os"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+// ChannelSnapshot is the state of a channel including the messages in flight
+type ChannelSnapshot struct {
+	To       int
+	Delay    int
+	Policy   Policy
+	Weight   fixed.Fixed
+	Time     uint64
+	Messages []Message
+	Dropped  uint64
+	Pending  []fixed.Fixed
+}
+
+// HarmonicSnapshot is the state of a harmonic node
+type HarmonicSnapshot struct {
+	Note                 uint8
+	States               [2]fixed.Fixed
+	Weights              [4]fixed.Fixed
+	Oscillator           Oscillator
+	Outbox               []ChannelSnapshot
+	Absolute, Relative   int
+	Refractory, Recovery int
+	Adaptation, Adapted  fixed.Fixed
+	Mode                 Mode
+	Interval, Elapsed    int
+}
+
+// Snapshot is a serializable snapshot of the state of a harmonic network
+type Snapshot struct {
+	Backend Backend
+	Nodes   []HarmonicSnapshot
+}
+
+// Snapshot captures the state of the harmonic network
+func (h HarmonicNetwork) Snapshot() *Snapshot {
+	nodes := make([]HarmonicSnapshot, len(h))
+	for i := range h {
+		nodes[i] = HarmonicSnapshot{
+			Note:       h[i].Note,
+			States:     h[i].States,
+			Weights:    h[i].Weights,
+			Outbox:     make([]ChannelSnapshot, len(h[i].Outbox)),
+			Absolute:   h[i].Absolute,
+			Relative:   h[i].Relative,
+			Refractory: h[i].Refractory,
+			Recovery:   h[i].Recovery,
+			Adaptation: h[i].Adaptation,
+			Adapted:    h[i].Adapted,
+			Mode:       h[i].Mode,
+			Interval:   h[i].Interval,
+			Elapsed:    h[i].Elapsed,
+		}
+		if h[i].Oscillator != nil {
+			nodes[i].Oscillator = h[i].Oscillator.Clone()
+		}
+		for j, channel := range h[i].Outbox {
+			var pending []fixed.Fixed
+			for channel.Out.Len() > 0 {
+				value, _ := channel.Out.Poll()
+				pending = append(pending, value)
+			}
+			for _, value := range pending {
+				channel.Out.Offer(value)
+			}
+			nodes[i].Outbox[j] = ChannelSnapshot{
+				To:       channel.To,
+				Delay:    channel.Delay,
+				Policy:   channel.Policy,
+				Weight:   channel.Weight,
+				Time:     channel.Line.Time,
+				Messages: channel.Line.Pending(),
+				Dropped:  channel.Dropped,
+				Pending:  pending,
+			}
+		}
+	}
+	return &Snapshot{
+		Backend: h.Backend(),
+		Nodes:   nodes,
+	}
+}
+
+// NewHarmonicNetwork creates a harmonic network from a snapshot
+func (s *Snapshot) NewHarmonicNetwork() HarmonicNetwork {
+	network := make(HarmonicNetwork, len(s.Nodes))
+	for i, node := range s.Nodes {
+		network[i].Note = node.Note
+		network[i].States = node.States
+		network[i].Weights = node.Weights
+		if node.Oscillator != nil {
+			network[i].Oscillator = node.Oscillator.Clone()
+		}
+		network[i].Absolute, network[i].Relative = node.Absolute, node.Relative
+		network[i].Refractory, network[i].Recovery = node.Refractory, node.Recovery
+		network[i].Adaptation, network[i].Adapted = node.Adaptation, node.Adapted
+		network[i].Mode, network[i].Interval, network[i].Elapsed = node.Mode, node.Interval, node.Elapsed
+		for _, channel := range node.Outbox {
+			connection := NewLink(s.Backend)
+			for _, value := range channel.Pending {
+				connection.Offer(value)
+			}
+			restored := NewChannel(channel.To, channel.Delay, channel.Policy, connection)
+			if len(channel.Messages) > len(restored.Line.Messages) {
+				restored.Line = NewDelayLine(len(channel.Messages))
+			}
+			for _, message := range channel.Messages {
+				restored.Line.Push(message)
+			}
+			restored.Weight = channel.Weight
+			restored.Line.Time, restored.Dropped = channel.Time, channel.Dropped
+			network[i].Outbox = append(network[i].Outbox, restored)
+			network[channel.To].Inbox = append(network[channel.To].Inbox, connection)
+			network[channel.To].Gains = append(network[channel.To].Gains, restored.Weight)
+		}
+	}
+	return network
+}
+
+// Write writes the snapshot to a file
+func (s *Snapshot) Write(name string) {
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	encoder := gob.NewEncoder(out)
+	err = encoder.Encode(s)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReadSnapshot reads a snapshot from a file
+func ReadSnapshot(name string) *Snapshot {
+	snapshot := Snapshot{}
+	in, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	decoder := gob.NewDecoder(in)
+	err = decoder.Decode(&snapshot)
+	if err != nil {
+		panic(err)
+	}
+	return &snapshot
+}
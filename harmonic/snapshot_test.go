@@ -0,0 +1,28 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHarmonicNetwork_Snapshot(t *testing.T) {
+	genome := HarmonicGenomeFactory(rand.New(rand.NewSource(1))).(*HarmonicGenome)
+	a := genome.NewHarmonicNetwork()
+	for i := 0; i < 1000; i++ {
+		a.Step(nil)
+	}
+	b := a.Snapshot().NewHarmonicNetwork()
+	for i := 0; i < 1000; i++ {
+		a.Step(nil)
+		b.Step(nil)
+		for j := range a {
+			if a[j].States != b[j].States {
+				t.Fatalf("node %d differs at step %d", j, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+// Class is the stability class of a harmonic node
+type Class int
+
+const (
+	// Damped nodes decay to zero
+	Damped Class = iota
+	// Oscillatory nodes neither decay nor grow
+	Oscillatory
+	// Divergent nodes grow without bound
+	Divergent
+)
+
+// String converts the class to a string
+func (c Class) String() string {
+	switch c {
+	case Damped:
+		return "damped"
+	case Oscillatory:
+		return "oscillatory"
+	}
+	return "divergent"
+}
+
+// Stability is the linear stability of the uncoupled recurrence of a harmonic node
+type Stability struct {
+	Roots  [2]complex128
+	Radius float64
+	Class  Class
+}
+
+// Stability computes the roots of the characteristic polynomial z^2 - w0 z - w1 of the node's
+// recurrence, tolerance is the distance of the spectral radius from 1 that is considered oscillatory
+func (h *Harmonic) Stability(tolerance float64) Stability {
+	w0, w1 := complex(h.Weights[0].Float64(), 0), complex(h.Weights[1].Float64(), 0)
+	d := cmplx.Sqrt(w0*w0 + 4*w1)
+	roots := [2]complex128{(w0 + d) / 2, (w0 - d) / 2}
+	radius := math.Max(cmplx.Abs(roots[0]), cmplx.Abs(roots[1]))
+	class := Oscillatory
+	if radius < 1-tolerance {
+		class = Damped
+	} else if radius > 1+tolerance {
+		class = Divergent
+	}
+	return Stability{
+		Roots:  roots,
+		Radius: radius,
+		Class:  class,
+	}
+}
+
+// Clone creates an independent copy of the harmonic network including messages in flight
+func (h HarmonicNetwork) Clone() HarmonicNetwork {
+	return h.Snapshot().NewHarmonicNetwork()
+}
+
+// distance computes the euclidean distance between the node states of two networks
+func distance(a, b HarmonicNetwork) float64 {
+	sum := 0.0
+	for i := range a {
+		for j := range a[i].States {
+			d := (b[i].States[j] - a[i].States[j]).Float64()
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// Lyapunov estimates the maximal lyapunov exponent per step of the harmonic network by running a twin
+// trajectory perturbed by delta and renormalizing the separation every interval steps,
+// messages in flight are not renormalized and the exponent is negative infinity if the separation
+// contracts below the resolution of the fixed point states
+func (h HarmonicNetwork) Lyapunov(delta float64, interval, renormalizations int) float64 {
+	a := h.Clone()
+	b := a.Clone()
+	perturb := func() {
+		for i := range b {
+			for j := range b[i].States {
+				b[i].States[j] = a[i].States[j] + fixed.FixedFloat64(delta)
+			}
+		}
+	}
+	perturb()
+	d0, sum := distance(a, b), 0.0
+	for r := 0; r < renormalizations; r++ {
+		for i := 0; i < interval; i++ {
+			a.Step(nil)
+			b.Step(nil)
+		}
+		d := distance(a, b)
+		if d == 0 {
+			return math.Inf(-1)
+		}
+		sum += math.Log(d / d0)
+		scale := d0 / d
+		for i := range b {
+			for j := range b[i].States {
+				separation := (b[i].States[j] - a[i].States[j]).Float64()
+				b[i].States[j] = a[i].States[j] + fixed.FixedFloat64(separation*scale)
+			}
+		}
+		if distance(a, b) == 0 {
+			perturb()
+		}
+	}
+	return sum / float64(renormalizations*interval)
+}
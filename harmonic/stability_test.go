@@ -0,0 +1,48 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harmonic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestHarmonic_Stability(t *testing.T) {
+	vectors := [...]struct {
+		w0, w1 float64
+		class  Class
+	}{
+		{1.9, -1, Oscillatory},
+		{1, -.5, Damped},
+		{2.5, -1, Divergent},
+		{.5, .75, Divergent},
+	}
+	for _, v := range vectors {
+		h := Harmonic{}
+		h.Weights[0], h.Weights[1] = fixed.FixedFloat64(v.w0), fixed.FixedFloat64(v.w1)
+		if s := h.Stability(1e-3); s.Class != v.class {
+			t.Errorf("%f %f is %s not %s", v.w0, v.w1, s.Class, v.class)
+		}
+	}
+}
+
+func TestHarmonicNetwork_Lyapunov(t *testing.T) {
+	node := func(w0 float64) HarmonicNetwork {
+		h := Harmonic{States: [2]fixed.Fixed{fixed.FixedFloat64(.01), 0}}
+		h.Weights = [4]fixed.Fixed{fixed.FixedFloat64(w0), 0, 0, 1 << 30}
+		return HarmonicNetwork{h}
+	}
+	if l := node(.9).Lyapunov(1e-2, 10, 10); l >= 0 || math.Abs(l-math.Log(.9)) > .02 {
+		t.Errorf("the damped node has exponent %f not %f", l, math.Log(.9))
+	}
+	if l := node(1.1).Lyapunov(1e-2, 10, 10); l <= 0 || math.Abs(l-math.Log(1.1)) > .02 {
+		t.Errorf("the divergent node has exponent %f not %f", l, math.Log(1.1))
+	}
+	if l := node(.1).Lyapunov(1e-3, 10, 10); !math.IsInf(l, -1) {
+		t.Errorf("the merged twins have exponent %f", l)
+	}
+}
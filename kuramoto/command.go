@@ -0,0 +1,175 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kuramoto
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/MaxHalford/eaopt"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	NetworkSize = 7
+	Iterations  = 10000
+	// Oscillators is the number of oscillators of the critical coupling bench
+	Oscillators = 512
+	// Gamma is the half width of the frequency distribution of the critical coupling bench
+	Gamma = .5
+)
+
+var (
+	Notes = [...]uint8{
+		60,
+		62,
+		64,
+		65,
+		67,
+		69,
+		71,
+	}
+	MaxSpectrumEntropy = math.Log(Iterations)
+)
+
+// Bench compares the order parameter of a mean field kuramoto network against the critical coupling theory
+func Bench() {
+	frequencies := Lorentzian(Oscillators, Gamma)
+	measured, theory := make(plotter.XYs, 0, 16), make(plotter.XYs, 0, 16)
+	for i := 0; i <= 16; i++ {
+		coupling := float64(i) * 4 * Gamma / 16
+		r, expected := MeanField(frequencies, coupling, 8000), Critical(Gamma, coupling)
+		fmt.Printf("coupling=%f r=%f theory=%f\n", coupling, r, expected)
+		measured = append(measured, plotter.XY{X: coupling, Y: r})
+		theory = append(theory, plotter.XY{X: coupling, Y: expected})
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+
+	p.Title.Text = "order"
+	p.X.Label.Text = "coupling"
+	p.Y.Label.Text = "order"
+
+	scatter, err := plotter.NewScatter(measured)
+	if err != nil {
+		panic(err)
+	}
+	p.Add(scatter)
+
+	line, err := plotter.NewLine(theory)
+	if err != nil {
+		panic(err)
+	}
+	p.Add(line)
+
+	err = p.Save(8*vg.Inch, 8*vg.Inch, "kuramoto_order.png")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Learn evolves a kuramoto network and writes the best genome to best_kuramoto.net
+func Learn() {
+	ga, err := eaopt.NewDefaultGAConfig().NewGA()
+	if err != nil {
+		panic(err)
+	}
+
+	ga.NGenerations = 200
+	ga.RNG = rand.New(rand.NewSource(1))
+	ga.ParallelEval = true
+	ga.PopSize = 100
+
+	ga.Callback = func(ga *eaopt.GA) {
+		fmt.Printf("Best fitness at generation %d: %f\n", ga.Generations, ga.HallOfFame[0].Fitness)
+		fmt.Println(ga.HallOfFame[0].Genome.(*Genome).Delays.String())
+	}
+	ga.EarlyStop = func(ga *eaopt.GA) bool {
+		return ga.HallOfFame[0].Fitness < 0.00001
+	}
+
+	err = ga.Minimize(GenomeFactory)
+	if err != nil {
+		panic(err)
+	}
+
+	best := ga.HallOfFame[0].Genome.(*Genome)
+	best.Write("best_kuramoto.net")
+}
+
+// Inference runs the kuramoto network of a genome file and plots its order parameter over time
+func Inference(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadGenome(name)
+	network := genome.NewNetwork()
+	points := make(plotter.XYs, 0, Iterations)
+	for i := 0; i < Iterations; i++ {
+		notes := network.Step(nil)
+		for _, note := range notes {
+			fmt.Printf(" %d", note)
+		}
+		r, _ := network.Order()
+		points = append(points, plotter.XY{X: float64(i), Y: r})
+	}
+	fmt.Printf("\n")
+
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+
+	p.Title.Text = "order"
+	p.X.Label.Text = "time"
+	p.Y.Label.Text = "order"
+
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		panic(err)
+	}
+	p.Add(line)
+
+	err = p.Save(8*vg.Inch, 8*vg.Inch, "kuramoto_order_time.png")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Analyze computes the mean order parameter and the effective frequencies of a kuramoto network
+func Analyze(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadGenome(name)
+	network := genome.NewNetwork()
+	turns, sum := make([]int, len(network.Oscillators)), 0.0
+	for i := 0; i < Iterations; i++ {
+		previous := make([]float64, len(network.Oscillators))
+		for j := range network.Oscillators {
+			previous[j] = network.Oscillators[j].Phase.Float64()
+		}
+		network.Step(nil)
+		for j := range network.Oscillators {
+			if network.Oscillators[j].Phase.Float64() < previous[j]-.5 {
+				turns[j]++
+			}
+		}
+		r, _ := network.Order()
+		sum += r
+	}
+	duration := Iterations * Dt.Float64()
+	for i, oscillator := range network.Oscillators {
+		fmt.Printf("oscillator %d: natural=%f effective=%f\n", i, oscillator.Frequency.Float64(),
+			2*math.Pi*float64(turns[i])/duration)
+	}
+	fmt.Printf("order=%f\n", sum/Iterations)
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kuramoto
+
+import (
+	"math"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+// MaxFrequency clips the tails of the lorentzian frequency distribution
+const MaxFrequency = 64
+
+// Lorentzian returns n natural frequencies at the quantiles of a lorentzian distribution
+// of half width gamma, for which the critical coupling is 2 gamma
+func Lorentzian(n int, gamma float64) []fixed.Fixed {
+	frequencies := make([]fixed.Fixed, n)
+	for i := range frequencies {
+		frequency := gamma * math.Tan(math.Pi*(float64(i)+.5)/float64(n)-math.Pi/2)
+		frequency = math.Max(-MaxFrequency, math.Min(MaxFrequency, frequency))
+		frequencies[i] = fixed.FixedFloat64(frequency)
+	}
+	return frequencies
+}
+
+// MeanField runs an all to all kuramoto network with global coupling using the order parameter
+// and returns the order parameter averaged over the second half of the steps
+func MeanField(frequencies []fixed.Fixed, coupling float64, steps int) float64 {
+	phases, k := make([]fixed.Fixed, len(frequencies)), fixed.FixedFloat64(coupling)
+	for i := range phases {
+		phases[i] = fixed.Fixed(i * Turn / len(phases))
+	}
+	size, sum := fixed.Fixed(len(phases)), 0.0
+	for step := 0; step < steps; step++ {
+		x, y := fixed.Fixed(0), fixed.Fixed(0)
+		for _, phase := range phases {
+			x += Cos(phase)
+			y += Sin(phase)
+		}
+		x, y = x/size, y/size
+		if step >= steps/2 {
+			sum += math.Hypot(x.Float64(), y.Float64())
+		}
+		for i, phase := range phases {
+			force := k.Mul(y.Mul(Cos(phase)) - x.Mul(Sin(phase)))
+			phases[i] = (phase + Dt.Mul(inverseTwoPi.Mul(frequencies[i]+force))) & (Turn - 1)
+		}
+	}
+	return sum / float64(steps-steps/2)
+}
+
+// Critical is the theoretical order parameter of an infinite kuramoto network with lorentzian frequencies
+func Critical(gamma, coupling float64) float64 {
+	critical := 2 * gamma
+	if coupling <= critical {
+		return 0
+	}
+	return math.Sqrt(1 - critical/coupling)
+}
@@ -0,0 +1,273 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kuramoto
+
+import (
+	"encoding/gob"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/pointlander/sync/fixed"
+	"github.com/pointlander/sync/harmonic"
+	"github.com/pointlander/sync/slices"
+
+	"github.com/MaxHalford/eaopt"
+	"github.com/mjibson/go-dsp/fft"
+)
+
+const (
+	// Dt is the integration time step
+	Dt fixed.Fixed = fixed.FixedOne >> 4
+	// HistorySize is the number of past phases kept for delayed coupling
+	HistorySize = 256
+	// SineBits is the log2 of the size of the sine table
+	SineBits = 10
+	// SineSize is the size of the sine table
+	SineSize = 1 << SineBits
+	// Turn is a full turn of phase
+	Turn = fixed.FixedOne
+)
+
+var (
+	sine         [SineSize]fixed.Fixed
+	inverseTwoPi = fixed.FixedFloat64(1 / (2 * math.Pi))
+)
+
+func init() {
+	for i := range sine {
+		sine[i] = fixed.FixedFloat64(math.Sin(2 * math.Pi * float64(i) / SineSize))
+	}
+}
+
+// Sin computes the sine of a phase in turns
+func Sin(phase fixed.Fixed) fixed.Fixed {
+	return sine[(phase&(Turn-1))>>(fixed.Places-SineBits)]
+}
+
+// Cos computes the cosine of a phase in turns
+func Cos(phase fixed.Fixed) fixed.Fixed {
+	return Sin(phase + Turn/4)
+}
+
+// Edge is a delayed coupling from another phase oscillator
+type Edge struct {
+	From     int
+	Delay    uint8
+	Coupling fixed.Fixed
+}
+
+// Oscillator is a phase oscillator
+type Oscillator struct {
+	Note uint8
+	// Phase is in turns and Frequency is in radians per unit time
+	Phase     fixed.Fixed
+	Frequency fixed.Fixed
+	Inbox     []Edge
+	History   [HistorySize]fixed.Fixed
+}
+
+// Network is a network of phase oscillators
+type Network struct {
+	Oscillators []Oscillator
+	Time        int
+}
+
+// Genome is a genome representing the parameters of a kuramoto network
+type Genome struct {
+	Frequencies slices.Fixed
+	Phases      slices.Fixed
+	Couplings   slices.Fixed
+	Delays      slices.Uint8
+}
+
+// NewNetwork creates a kuramoto network for a genome
+func (g *Genome) NewNetwork() *Network {
+	oscillators, c := make([]Oscillator, NetworkSize), 0
+	for i := range oscillators {
+		for j := range oscillators {
+			if delay := g.Delays[c]; i != j && delay < 255 {
+				oscillators[i].Inbox = append(oscillators[i].Inbox, Edge{
+					From:     j,
+					Delay:    delay,
+					Coupling: g.Couplings[c],
+				})
+			}
+			c++
+		}
+		oscillators[i].Phase = g.Phases[i] & (Turn - 1)
+		oscillators[i].Frequency = g.Frequencies[i]
+		for j := range oscillators[i].History {
+			oscillators[i].History[j] = oscillators[i].Phase
+		}
+	}
+	for i, note := range Notes {
+		oscillators[i].Note = note
+	}
+	return &Network{
+		Oscillators: oscillators,
+	}
+}
+
+// Step steps the phases of the kuramoto network, an oscillator fires when its phase completes a turn
+func (n *Network) Step(states [][]float64) (notes []uint8) {
+	oscillators, now := n.Oscillators, n.Time%HistorySize
+	for i := range oscillators {
+		oscillators[i].History[now] = oscillators[i].Phase
+	}
+	var (
+		max  fixed.Fixed
+		note uint8
+	)
+	size := fixed.Fixed(len(oscillators))
+	for i := range oscillators {
+		oscillator, sum := &oscillators[i], fixed.Fixed(0)
+		for _, edge := range oscillator.Inbox {
+			phase := oscillators[edge.From].History[(now-int(edge.Delay)+HistorySize)%HistorySize]
+			sum += edge.Coupling.Mul(Sin(phase - oscillator.Phase))
+		}
+		advance := Dt.Mul(inverseTwoPi.Mul(oscillator.Frequency + sum/size))
+		phase := oscillator.Phase + advance
+		if phase >= Turn && advance > max {
+			max, note = advance, oscillator.Note
+		}
+		oscillator.Phase = phase & (Turn - 1)
+		if states != nil {
+			states[i] = append(states[i], Sin(oscillator.Phase).Float64())
+		}
+	}
+	n.Time++
+	if note != 0 {
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// Order computes the kuramoto order parameter r and the mean phase psi in radians
+func (n *Network) Order() (r, psi float64) {
+	x, y := 0.0, 0.0
+	for _, oscillator := range n.Oscillators {
+		x += Cos(oscillator.Phase).Float64()
+		y += Sin(oscillator.Phase).Float64()
+	}
+	size := float64(len(n.Oscillators))
+	x, y = x/size, y/size
+	return math.Sqrt(x*x + y*y), math.Atan2(y, x)
+}
+
+// Evaluate computes the fitness of the genome
+func (g *Genome) Evaluate() (float64, error) {
+	network := g.NewNetwork()
+	data := make([][]float64, len(network.Oscillators))
+	for i := range data {
+		data[i] = make([]float64, 0, Iterations)
+	}
+	for i := 0; i < Iterations; i++ {
+		network.Step(data)
+	}
+	sum := 0.0
+	for _, values := range data {
+		fit := harmonic.Entropy(fft.FFTReal(values))/MaxSpectrumEntropy - .5
+		sum += fit * fit
+	}
+	return sum / float64(len(data)), nil
+}
+
+// Mutate mutates the genome
+func (g *Genome) Mutate(rng *rand.Rand) {
+	eaopt.MutPermute(g.Frequencies, 1, rng)
+	eaopt.MutPermute(g.Phases, 1, rng)
+	eaopt.MutPermute(g.Couplings, 1, rng)
+	eaopt.MutPermute(g.Delays, 1, rng)
+}
+
+// Crossover mates two genomes
+func (g *Genome) Crossover(r eaopt.Genome, rng *rand.Rand) {
+	eaopt.CrossGNX(g.Frequencies, r.(*Genome).Frequencies, 1, rng)
+	eaopt.CrossGNX(g.Phases, r.(*Genome).Phases, 1, rng)
+	eaopt.CrossGNX(g.Couplings, r.(*Genome).Couplings, 1, rng)
+	eaopt.CrossGNX(g.Delays, r.(*Genome).Delays, 1, rng)
+}
+
+// Clone produces a copy of a genome
+func (g *Genome) Clone() eaopt.Genome {
+	frequencies := make(slices.Fixed, len(g.Frequencies))
+	phases := make(slices.Fixed, len(g.Phases))
+	couplings := make(slices.Fixed, len(g.Couplings))
+	delays := make(slices.Uint8, len(g.Delays))
+	copy(frequencies, g.Frequencies)
+	copy(phases, g.Phases)
+	copy(couplings, g.Couplings)
+	copy(delays, g.Delays)
+	return &Genome{
+		Frequencies: frequencies,
+		Phases:      phases,
+		Couplings:   couplings,
+		Delays:      delays,
+	}
+}
+
+// Write writes the genome to a file
+func (g *Genome) Write(name string) {
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	encoder := gob.NewEncoder(out)
+	err = encoder.Encode(g)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReadGenome reads a genome from a file
+func ReadGenome(name string) *Genome {
+	genome := Genome{}
+	in, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	decoder := gob.NewDecoder(in)
+	err = decoder.Decode(&genome)
+	if err != nil {
+		panic(err)
+	}
+	return &genome
+}
+
+// GenomeFactory creates a new genome
+func GenomeFactory(rnd *rand.Rand) eaopt.Genome {
+	frequencies := make(slices.Fixed, NetworkSize)
+	for i := range frequencies {
+		frequencies[i] = fixed.Fixed(rnd.Intn(2 * fixed.FixedOne))
+	}
+	phases := make(slices.Fixed, NetworkSize)
+	for i := range phases {
+		phases[i] = fixed.Fixed(rnd.Intn(Turn))
+	}
+	couplings := make(slices.Fixed, NetworkSize*NetworkSize)
+	for i := range couplings {
+		couplings[i] = fixed.Fixed(rnd.Intn(4 * fixed.FixedOne))
+		if rnd.Intn(2) == 0 {
+			couplings[i] = -couplings[i]
+		}
+	}
+	delays := make(slices.Uint8, NetworkSize*NetworkSize)
+	for i := range delays {
+		if rnd.Intn(2) == 0 {
+			delays[i] = 255
+		} else {
+			delays[i] = uint8(rnd.Intn(255))
+		}
+	}
+	return &Genome{
+		Frequencies: frequencies,
+		Phases:      phases,
+		Couplings:   couplings,
+		Delays:      delays,
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kuramoto
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMeanField(t *testing.T) {
+	frequencies := Lorentzian(Oscillators, Gamma)
+	if r := MeanField(frequencies, Gamma, 8000); r > .15 {
+		t.Fatalf("order %f below the critical coupling", r)
+	}
+	for _, coupling := range []float64{3 * Gamma, 4 * Gamma} {
+		r, expected := MeanField(frequencies, coupling, 8000), Critical(Gamma, coupling)
+		if math.Abs(r-expected) > .1 {
+			t.Fatalf("order %f expected %f at coupling %f", r, expected, coupling)
+		}
+	}
+}
+
+func TestNetwork_Step(t *testing.T) {
+	genome := GenomeFactory(rand.New(rand.NewSource(1))).(*Genome)
+	for i := range genome.Frequencies {
+		genome.Frequencies[i] = genome.Frequencies[0]
+	}
+	for i := range genome.Couplings {
+		genome.Couplings[i] = Turn
+		genome.Delays[i] = 0
+	}
+	network := genome.NewNetwork()
+	for i := 0; i < Iterations; i++ {
+		network.Step(nil)
+	}
+	if r, _ := network.Order(); r < .99 {
+		t.Fatalf("identical oscillators with attractive coupling should synchronize r=%f", r)
+	}
+}
@@ -9,19 +9,34 @@ import (
 
 	"github.com/pointlander/sync/cellular"
 	"github.com/pointlander/sync/harmonic"
+	"github.com/pointlander/sync/kuramoto"
+	"github.com/pointlander/sync/reservoir"
+	"github.com/pointlander/sync/spiking"
 )
 
 var options = struct {
 	bench     *bool
 	learn     *bool
 	inference *bool
+	analyze   *bool
+	rules     *bool
+	async     *bool
+	events    *bool
+	compare   *bool
+	interval  *int
 	mode      *string
 	net       *string
 }{
 	bench:     flag.Bool("bench", false, "run the test bench"),
 	learn:     flag.Bool("learn", false, "learn a network"),
 	inference: flag.Bool("inference", false, "run inference on a network"),
-	mode:      flag.String("mode", "harmonic", "harmonic or cellular"),
+	analyze:   flag.Bool("analyze", false, "analyze the dynamics of a network"),
+	rules:     flag.Bool("rules", false, "classify the cellular automaton rules"),
+	async:     flag.Bool("async", false, "run a harmonic network with a goroutine per node"),
+	events:    flag.Bool("events", false, "run a harmonic network with the discrete event scheduler"),
+	compare:   flag.Bool("compare", false, "compare pulse and diffusive coupling of a harmonic network"),
+	interval:  flag.Int("interval", 1, "steps between diffusive messages"),
+	mode:      flag.String("mode", "harmonic", "harmonic, cellular, kuramoto, spiking or reservoir"),
 	net:       flag.String("net", "", "net file to load"),
 }
 
@@ -43,6 +58,16 @@ func main() {
 			cellular.Inference(*options.net)
 			return
 		}
+
+		if *options.analyze {
+			cellular.Analyze(*options.net)
+			return
+		}
+
+		if *options.rules {
+			cellular.Rules()
+			return
+		}
 	} else if *options.mode == "harmonic" {
 		if *options.bench {
 			harmonic.Bench()
@@ -58,5 +83,65 @@ func main() {
 			harmonic.Inference(*options.net)
 			return
 		}
+
+		if *options.analyze {
+			harmonic.Analyze(*options.net)
+			return
+		}
+
+		if *options.async {
+			harmonic.Async(*options.net)
+			return
+		}
+
+		if *options.events {
+			harmonic.Events(*options.net)
+			return
+		}
+
+		if *options.compare {
+			harmonic.Compare(*options.net, *options.interval)
+			return
+		}
+	} else if *options.mode == "kuramoto" {
+		if *options.bench {
+			kuramoto.Bench()
+			return
+		}
+
+		if *options.learn {
+			kuramoto.Learn()
+			return
+		}
+
+		if *options.inference {
+			kuramoto.Inference(*options.net)
+			return
+		}
+
+		if *options.analyze {
+			kuramoto.Analyze(*options.net)
+			return
+		}
+	} else if *options.mode == "spiking" {
+		if *options.bench {
+			spiking.Bench()
+			return
+		}
+
+		if *options.learn {
+			spiking.Learn()
+			return
+		}
+
+		if *options.inference {
+			spiking.Inference(*options.net)
+			return
+		}
+	} else if *options.mode == "reservoir" {
+		if *options.bench {
+			reservoir.Bench()
+			return
+		}
 	}
 }
@@ -0,0 +1,65 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/pointlander/sync/cellular"
+	"github.com/pointlander/sync/fixed"
+	"github.com/pointlander/sync/harmonic"
+)
+
+const (
+	// Length is the length of the benchmark tasks
+	Length = 4000
+	// Delays is the maximum delay for memory capacity
+	Delays = 40
+)
+
+// NewHarmonicReservoir creates a harmonic reservoir from a random genome
+func NewHarmonicReservoir(seed int64) Reservoir {
+	genome := harmonic.HarmonicGenomeFactory(rand.New(rand.NewSource(seed))).(*harmonic.HarmonicGenome)
+	return &HarmonicReservoir{
+		Network: genome.NewHarmonicNetwork(),
+		Nodes:   []int{0},
+		Gain:    fixed.Fixed(harmonic.Threshold).Float64(),
+	}
+}
+
+// NewCellularReservoir creates a cellular reservoir from a ring network
+func NewCellularReservoir(seed int64) Reservoir {
+	network := cellular.NewNetwork(int(seed), cellular.NetworkSize)
+	for i := range network.Neurons {
+		network.Neurons[i].AddConnection((i + cellular.NetworkSize - 1) % cellular.NetworkSize)
+		network.Neurons[i].AddConnection((i + 1) % cellular.NetworkSize)
+	}
+	return &CellularReservoir{
+		Simulator: cellular.NewSimulator(network),
+		Neurons:   []int{0},
+	}
+}
+
+// Bench runs the benchmark tasks on the harmonic and cellular reservoirs
+func Bench() {
+	reservoirs := []struct {
+		Name string
+		New  func(seed int64) Reservoir
+	}{
+		{"harmonic", NewHarmonicReservoir},
+		{"cellular", NewCellularReservoir},
+	}
+	for _, r := range reservoirs {
+		inputs, targets := NARMA10(rand.New(rand.NewSource(1)), Length)
+		fmt.Printf("%s narma10 nmse=%f\n", r.Name, NMSE(r.New(1), inputs, targets))
+
+		capacity := MemoryCapacity(r.New(1), rand.New(rand.NewSource(1)), Length, Delays)
+		fmt.Printf("%s memory capacity=%f\n", r.Name, capacity)
+
+		inputs, targets = DelayedXOR(rand.New(rand.NewSource(1)), Length, 1)
+		fmt.Printf("%s delayed xor accuracy=%f\n", r.Name, Accuracy(r.New(1), inputs, targets))
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"math"
+)
+
+// Readout is a linear readout of reservoir features
+type Readout struct {
+	Weights []float64
+}
+
+// Predict computes the linear output for the features
+func (r *Readout) Predict(features []float64) float64 {
+	sum := 0.0
+	for i, w := range r.Weights {
+		sum += w * features[i]
+	}
+	return sum
+}
+
+// Probability computes the logistic output for the features
+func (r *Readout) Probability(features []float64) float64 {
+	return 1 / (1 + math.Exp(-r.Predict(features)))
+}
+
+// Ridge fits a linear readout with ridge regression
+func Ridge(x [][]float64, y []float64, lambda float64) Readout {
+	n := len(x[0])
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	for t, row := range x {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += row[i] * row[j]
+			}
+			a[i][n] += row[i] * y[t]
+		}
+	}
+	for i := 0; i < n; i++ {
+		a[i][i] += lambda
+	}
+	return Readout{Weights: solve(a)}
+}
+
+// Logistic fits a logistic readout with gradient descent on targets that are 0 or 1
+func Logistic(x [][]float64, y []float64, lambda float64, iterations int, rate float64) Readout {
+	n, count := len(x[0]), float64(len(x))
+	readout := Readout{Weights: make([]float64, n)}
+	gradient := make([]float64, n)
+	for i := 0; i < iterations; i++ {
+		for j := range gradient {
+			gradient[j] = lambda * readout.Weights[j]
+		}
+		for t, row := range x {
+			e := readout.Probability(row) - y[t]
+			for j, value := range row {
+				gradient[j] += e * value / count
+			}
+		}
+		for j, g := range gradient {
+			readout.Weights[j] -= rate * g
+		}
+	}
+	return readout
+}
+
+// solve solves an augmented linear system with gaussian elimination
+func solve(a [][]float64) []float64 {
+	n := len(a)
+	for i := 0; i < n; i++ {
+		pivot := i
+		for j := i + 1; j < n; j++ {
+			if math.Abs(a[j][i]) > math.Abs(a[pivot][i]) {
+				pivot = j
+			}
+		}
+		a[i], a[pivot] = a[pivot], a[i]
+		if a[i][i] == 0 {
+			continue
+		}
+		for j := i + 1; j < n; j++ {
+			f := a[j][i] / a[i][i]
+			for k := i; k <= n; k++ {
+				a[j][k] -= f * a[i][k]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		if a[i][i] == 0 {
+			continue
+		}
+		sum := a[i][n]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i][j] * x[j]
+		}
+		x[i] = sum / a[i][i]
+	}
+	return x
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"github.com/pointlander/sync/cellular"
+	"github.com/pointlander/sync/fixed"
+	"github.com/pointlander/sync/harmonic"
+)
+
+// Reservoir is a dynamical system that can be driven by an input and observed
+type Reservoir interface {
+	Step(input float64) []float64
+}
+
+// HarmonicReservoir is a harmonic network used as a reservoir
+type HarmonicReservoir struct {
+	Network harmonic.HarmonicNetwork
+	Nodes   []int
+	Gain    float64
+}
+
+// Step adds the scaled input to the input nodes, steps the network and returns the node states
+func (r *HarmonicReservoir) Step(input float64) []float64 {
+	value, inputs := fixed.FixedFloat64(r.Gain*input), make([]harmonic.Input, len(r.Nodes))
+	for i, node := range r.Nodes {
+		inputs[i] = harmonic.Input{Node: node, Series: []fixed.Fixed{value}}
+	}
+	r.Network.StepInputs(nil, inputs, 0)
+	features := make([]float64, 0, 2*len(r.Network))
+	for i := range r.Network {
+		features = append(features, r.Network[i].States[0].Float64(), r.Network[i].States[1].Float64())
+	}
+	return features
+}
+
+// CellularReservoir is a network of cellular automatons used as a reservoir
+type CellularReservoir struct {
+	Simulator *cellular.Simulator
+	Neurons   []int
+}
+
+// Step injects the input in [0, 1] as a thermometer code into the first chunk of the input neurons,
+// steps the network and returns the density and complexity of each neuron
+func (r *CellularReservoir) Step(input float64) []float64 {
+	network := &r.Simulator.Network
+	if input < 0 {
+		input = 0
+	} else if input > 1 {
+		input = 1
+	}
+	for _, neuron := range r.Neurons {
+		width := network.Neurons[neuron].Size
+		if width > cellular.ChunkSize {
+			width = cellular.ChunkSize
+		}
+		ones, pattern := uint(input*float64(width)+.5), ^uint64(0)
+		if ones < 64 {
+			pattern = (uint64(1) << ones) - 1
+		}
+		mask := ^uint64(0)
+		if width < 64 {
+			mask = (uint64(1) << uint(width)) - 1
+		}
+		drive := cellular.Input{Neuron: neuron, Mask: mask, Series: []uint64{pattern}}
+		drive.Inject(network, 0)
+	}
+	r.Simulator.Step()
+	features := make([]float64, 0, 2*len(network.Neurons))
+	for _, ca := range network.Neurons {
+		features = append(features, float64(ca.On)/float64(ca.Size), ca.Complexity)
+	}
+	return features
+}
+
+// Collect drives the reservoir with the inputs and returns the features with a bias term
+func Collect(r Reservoir, inputs []float64) [][]float64 {
+	features := make([][]float64, len(inputs))
+	for t, input := range inputs {
+		features[t] = append(r.Step(input), 1)
+	}
+	return features
+}
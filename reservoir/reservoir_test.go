@@ -0,0 +1,44 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// delayLine is a reservoir that remembers its recent inputs
+type delayLine []float64
+
+func (d delayLine) Step(input float64) []float64 {
+	copy(d[1:], d[:len(d)-1])
+	d[0] = input
+	features := make([]float64, len(d))
+	copy(features, d)
+	return features
+}
+
+func TestRidge(t *testing.T) {
+	x, y := make([][]float64, 0, 100), make([]float64, 0, 100)
+	for i := 0; i < 100; i++ {
+		a, b := float64(i), float64(i*i%7)
+		x = append(x, []float64{a, b, 1})
+		y = append(y, 2*a-3*b+5)
+	}
+	readout := Ridge(x, y, 0)
+	for i, w := range [...]float64{2, -3, 5} {
+		if math.Abs(readout.Weights[i]-w) > 1e-6 {
+			t.Fatalf("weight %d is %f not %f", i, readout.Weights[i], w)
+		}
+	}
+}
+
+func TestMemoryCapacity(t *testing.T) {
+	capacity := MemoryCapacity(make(delayLine, 10), rand.New(rand.NewSource(1)), 2000, 20)
+	if capacity < 8.9 || capacity > 10 {
+		t.Fatalf("capacity of a delay line with 9 delayed taps is %f", capacity)
+	}
+}
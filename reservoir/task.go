@@ -0,0 +1,122 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reservoir
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/pointlander/sync/util"
+)
+
+const (
+	// Washout is the number of initial steps that are not used for training
+	Washout = 200
+	// Lambda is the ridge regression regularization
+	Lambda = 1e-6
+)
+
+// NARMA10 generates inputs and targets for the tenth order nonlinear autoregressive moving average task,
+// the target at time t is the next output of the system
+func NARMA10(rnd *rand.Rand, length int) (inputs, targets []float64) {
+	inputs, y := make([]float64, length), make([]float64, length+1)
+	for t := range inputs {
+		inputs[t] = rnd.Float64() / 2
+	}
+	for t := 9; t < length; t++ {
+		sum := 0.0
+		for i := 0; i < 10; i++ {
+			sum += y[t-i]
+		}
+		y[t+1] = .3*y[t] + .05*y[t]*sum + 1.5*inputs[t-9]*inputs[t] + .1
+	}
+	return inputs, y[1:]
+}
+
+// DelayedXOR generates random bits and targets that are the xor of the bits delay and delay+1 steps ago
+func DelayedXOR(rnd *rand.Rand, length, delay int) (inputs, targets []float64) {
+	inputs, targets = make([]float64, length), make([]float64, length)
+	for t := range inputs {
+		inputs[t] = float64(rnd.Intn(2))
+		if t > delay {
+			targets[t] = float64(int(inputs[t-delay]) ^ int(inputs[t-delay-1]))
+		}
+	}
+	return inputs, targets
+}
+
+// split splits the collected features and targets into training and testing halves after the washout
+func split(features [][]float64, targets []float64) (trainX [][]float64, trainY []float64, testX [][]float64, testY []float64) {
+	features, targets = features[Washout:], targets[Washout:]
+	half := len(features) / 2
+	return features[:half], targets[:half], features[half:], targets[half:]
+}
+
+// NMSE computes the normalized mean squared error of a linear readout trained on the first half
+// of the run and tested on the second half
+func NMSE(r Reservoir, inputs, targets []float64) float64 {
+	trainX, trainY, testX, testY := split(Collect(r, inputs), targets)
+	readout := Ridge(trainX, trainY, Lambda)
+	_, variance := util.MeanVariance(testY)
+	sum := 0.0
+	for t, x := range testX {
+		e := readout.Predict(x) - testY[t]
+		sum += e * e
+	}
+	return sum / float64(len(testX)) / variance
+}
+
+// Accuracy computes the classification accuracy of a logistic readout trained on the first half
+// of the run and tested on the second half
+func Accuracy(r Reservoir, inputs, targets []float64) float64 {
+	trainX, trainY, testX, testY := split(Collect(r, inputs), targets)
+	readout := Logistic(trainX, trainY, Lambda, 1000, 1)
+	correct := 0
+	for t, x := range testX {
+		if (readout.Probability(x) > .5) == (testY[t] > .5) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(testX))
+}
+
+// MemoryCapacity computes the sum over delays of the squared correlation between
+// the delayed input and the output of a linear readout
+func MemoryCapacity(r Reservoir, rnd *rand.Rand, length, delays int) float64 {
+	inputs := make([]float64, length)
+	for t := range inputs {
+		inputs[t] = rnd.Float64()
+	}
+	features, capacity := Collect(r, inputs), 0.0
+	for k := 1; k <= delays; k++ {
+		targets := make([]float64, length)
+		for t := k; t < length; t++ {
+			targets[t] = inputs[t-k]
+		}
+		trainX, trainY, testX, testY := split(features, targets)
+		readout := Ridge(trainX, trainY, Lambda)
+		predictions := make([]float64, len(testX))
+		for t, x := range testX {
+			predictions[t] = readout.Predict(x)
+		}
+		r := correlation(predictions, testY)
+		capacity += r * r
+	}
+	return capacity
+}
+
+// correlation computes the pearson correlation of two series
+func correlation(a, b []float64) float64 {
+	meanA, varianceA := util.MeanVariance(a)
+	meanB, varianceB := util.MeanVariance(b)
+	if varianceA == 0 || varianceB == 0 {
+		return 0
+	}
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return sum / float64(len(a)) / math.Sqrt(varianceA*varianceB)
+}
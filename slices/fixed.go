@@ -55,7 +55,7 @@ func (f Fixed) Copy() eaopt.Slice {
 func (f Fixed) String() string {
 	series, space := "", ""
 	for _, value := range f {
-		series += fmt.Sprintf("%s%f", space, value)
+		series += fmt.Sprintf("%s%s", space, value)
 		space = " "
 	}
 	return series
@@ -0,0 +1,60 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slices
+
+import (
+	"fmt"
+
+	"github.com/MaxHalford/eaopt"
+)
+
+type Int []int
+
+func (s Int) At(i int) interface{} {
+	return s[i]
+}
+
+func (s Int) Set(i int, v interface{}) {
+	s[i] = v.(int)
+}
+
+func (s Int) Len() int {
+	return len(s)
+}
+
+func (s Int) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s Int) Slice(a, b int) eaopt.Slice {
+	return s[a:b]
+}
+
+func (s Int) Split(k int) (eaopt.Slice, eaopt.Slice) {
+	return s[:k], s[k:]
+}
+
+func (s Int) Append(t eaopt.Slice) eaopt.Slice {
+	return append(s, t.(Int)...)
+}
+
+func (s Int) Replace(t eaopt.Slice) {
+	copy(s, t.(Int))
+}
+
+func (s Int) Copy() eaopt.Slice {
+	t := make(Int, len(s))
+	copy(t, s)
+	return t
+}
+
+func (s Int) String() string {
+	series, space := "", ""
+	for _, value := range s {
+		series += fmt.Sprintf("%s%d", space, value)
+		space = " "
+	}
+	return series
+}
@@ -0,0 +1,168 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spiking
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/pointlander/sync/fixed"
+
+	"github.com/MaxHalford/eaopt"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	NetworkSize = 7
+	Iterations  = 10000
+	// MaxWeight is the maximum magnitude of a synaptic weight
+	MaxWeight = 16
+	// MaxBias is the maximum tonic input current of a neuron
+	MaxBias = 12
+)
+
+var (
+	Notes = [...]uint8{
+		60,
+		62,
+		64,
+		65,
+		67,
+		69,
+		71,
+	}
+	MaxSpectrumEntropy = math.Log(Iterations)
+	// Names are the names of the neuron types
+	Names = [NeuronTypes]string{"lif", "izhikevich"}
+)
+
+// Rate is the firing rate in hertz of a neuron driven by a constant input
+func Rate(neuron Neuron, input fixed.Fixed, steps int) float64 {
+	spikes := 0
+	for i := 0; i < steps; i++ {
+		if neuron.Step(input) {
+			spikes++
+		}
+	}
+	return 1000 * float64(spikes) / (float64(steps) * Dt.Float64())
+}
+
+// Bench computes the frequency current curves and plots the membrane potential of each neuron type
+func Bench() {
+	parameters := [Parameters]fixed.Fixed{fixed.FixedHalf, fixed.FixedHalf, 0, 0}
+	for kind, name := range Names {
+		for input := 0; input <= MaxBias; input += 2 {
+			rate := Rate(NewNeuron(uint8(kind), parameters), fixed.Fixed(input*fixed.FixedOne), Iterations)
+			fmt.Printf("%s: input=%d rate=%fHz\n", name, input, rate)
+		}
+
+		neuron, points := NewNeuron(uint8(kind), parameters), make(plotter.XYs, 0, 1000)
+		for i := 0; i < 1000; i++ {
+			neuron.Step(10 * fixed.FixedOne)
+			points = append(points, plotter.XY{X: float64(i) * Dt.Float64(), Y: neuron.Potential().Float64()})
+		}
+
+		p, err := plot.New()
+		if err != nil {
+			panic(err)
+		}
+
+		p.Title.Text = name
+		p.X.Label.Text = "time (ms)"
+		p.Y.Label.Text = "potential (mV)"
+
+		line, err := plotter.NewLine(points)
+		if err != nil {
+			panic(err)
+		}
+		p.Add(line)
+
+		err = p.Save(8*vg.Inch, 8*vg.Inch, fmt.Sprintf("spiking_%s.png", name))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Learn evolves a spiking network and writes the best genome to best_spiking.net
+func Learn() {
+	ga, err := eaopt.NewDefaultGAConfig().NewGA()
+	if err != nil {
+		panic(err)
+	}
+
+	ga.NGenerations = 200
+	ga.RNG = rand.New(rand.NewSource(1))
+	ga.ParallelEval = true
+	ga.PopSize = 100
+
+	ga.Callback = func(ga *eaopt.GA) {
+		fmt.Printf("Best fitness at generation %d: %f\n", ga.Generations, ga.HallOfFame[0].Fitness)
+		fmt.Println(ga.HallOfFame[0].Genome.(*Genome).Connections.String())
+	}
+	ga.EarlyStop = func(ga *eaopt.GA) bool {
+		return ga.HallOfFame[0].Fitness < 0.00001
+	}
+
+	err = ga.Minimize(GenomeFactory)
+	if err != nil {
+		panic(err)
+	}
+
+	best := ga.HallOfFame[0].Genome.(*Genome)
+	best.Write("best_spiking.net")
+}
+
+// Inference runs the spiking network of a genome file and plots a raster of its spikes
+func Inference(name string) {
+	if name == "" {
+		panic("net file required")
+	}
+	genome := ReadGenome(name)
+	network := genome.NewNetwork()
+	for i, neuron := range network.Neurons {
+		fmt.Printf("%d: %T %+v bias=%s\n", i, neuron, neuron, network.Bias[i])
+	}
+
+	points := make(plotter.XYs, 0, Iterations)
+	for i := 0; i < Iterations; i++ {
+		notes := network.Step(nil)
+		for _, note := range notes {
+			fmt.Printf(" %d", note)
+		}
+		for j, spiked := range network.Spikes {
+			if spiked {
+				points = append(points, plotter.XY{X: float64(i) * Dt.Float64(), Y: float64(j)})
+			}
+		}
+	}
+	fmt.Printf("\n")
+
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+
+	p.Title.Text = "raster"
+	p.X.Label.Text = "time (ms)"
+	p.Y.Label.Text = "neuron"
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		panic(err)
+	}
+	scatter.GlyphStyle.Radius = vg.Length(1)
+	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+	p.Add(scatter)
+
+	err = p.Save(8*vg.Inch, 8*vg.Inch, "spiking_raster.png")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spiking
+
+import (
+	"encoding/gob"
+	"math/rand"
+	"os"
+
+	"github.com/pointlander/sync/fixed"
+	"github.com/pointlander/sync/harmonic"
+	"github.com/pointlander/sync/slices"
+
+	"github.com/MaxHalford/eaopt"
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// Synapse is a delayed weighted connection to another neuron,
+// a spike sent at step t arrives at step t+max(Delay, 1)
+type Synapse struct {
+	To     int
+	Delay  int
+	Weight fixed.Fixed
+	Line   harmonic.DelayLine
+	// Dropped is the number of spikes dropped because the delay line was full
+	Dropped uint64
+}
+
+// NewSynapse creates a synapse with a delay line that can hold a spike from every step
+func NewSynapse(to, delay int, weight fixed.Fixed) Synapse {
+	return Synapse{
+		To:     to,
+		Delay:  delay,
+		Weight: weight,
+		Line:   harmonic.NewDelayLine(delay + 1),
+	}
+}
+
+// Send sends a spike down the synapse
+func (s *Synapse) Send() {
+	if s.Line.Full() {
+		s.Dropped++
+		return
+	}
+	s.Line.Push(harmonic.Message{
+		Time:  s.Line.Time + uint64(s.Delay),
+		Value: s.Weight,
+	})
+}
+
+// Step advances the synapse and returns the current of the spikes that arrived
+func (s *Synapse) Step() (current fixed.Fixed) {
+	s.Line.Time++
+	for s.Line.Due() {
+		current += s.Line.Pop().Value
+	}
+	return current
+}
+
+// Network is a network of spiking neurons
+type Network struct {
+	Neurons  []Neuron
+	Notes    []uint8
+	Bias     []fixed.Fixed
+	Synapses [][]Synapse
+	Input    []fixed.Fixed
+	// Spikes is true for each neuron that spiked in the last step
+	Spikes []bool
+}
+
+// Genome is a genome representing the parameters of a spiking network
+type Genome struct {
+	Connections slices.Uint8
+	Weights     slices.Fixed
+	Types       slices.Uint8
+	Parameters  slices.Fixed
+	Bias        slices.Fixed
+}
+
+// NewNetwork creates a spiking network for a genome
+func (g *Genome) NewNetwork() *Network {
+	network := &Network{
+		Neurons:  make([]Neuron, NetworkSize),
+		Notes:    make([]uint8, NetworkSize),
+		Bias:     make([]fixed.Fixed, NetworkSize),
+		Synapses: make([][]Synapse, NetworkSize),
+		Input:    make([]fixed.Fixed, NetworkSize),
+		Spikes:   make([]bool, NetworkSize),
+	}
+	c := 0
+	for i := range network.Neurons {
+		for j := range network.Neurons {
+			if delay := g.Connections[c]; i != j && delay < 255 {
+				network.Synapses[i] = append(network.Synapses[i], NewSynapse(j, int(delay), g.Weights[c]))
+			}
+			c++
+		}
+		var parameters [Parameters]fixed.Fixed
+		copy(parameters[:], g.Parameters[Parameters*i:])
+		network.Neurons[i] = NewNeuron(g.Types[i], parameters)
+		network.Bias[i] = g.Bias[i]
+	}
+	copy(network.Notes, Notes[:])
+	return network
+}
+
+// Step steps the spiking network, the note of the spiking neuron with the most input is played
+func (n *Network) Step(states [][]float64) (notes []uint8) {
+	for i := range n.Synapses {
+		for j := range n.Synapses[i] {
+			synapse := &n.Synapses[i][j]
+			n.Input[synapse.To] += synapse.Step()
+		}
+	}
+	var (
+		max  fixed.Fixed
+		note uint8
+	)
+	for i, neuron := range n.Neurons {
+		input := n.Bias[i] + n.Input[i]
+		n.Input[i] = 0
+		n.Spikes[i] = neuron.Step(input)
+		if n.Spikes[i] {
+			for j := range n.Synapses[i] {
+				n.Synapses[i][j].Send()
+			}
+			if note == 0 || input > max {
+				max, note = input, n.Notes[i]
+			}
+		}
+		if states != nil {
+			states[i] = append(states[i], neuron.Potential().Float64())
+		}
+	}
+	if note != 0 {
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// Evaluate computes the fitness of the genome
+func (g *Genome) Evaluate() (float64, error) {
+	network := g.NewNetwork()
+	data := make([][]float64, len(network.Neurons))
+	for i := range data {
+		data[i] = make([]float64, 0, Iterations)
+	}
+	for i := 0; i < Iterations; i++ {
+		network.Step(data)
+	}
+	sum := 0.0
+	for _, values := range data {
+		fit := harmonic.Entropy(fft.FFTReal(values))/MaxSpectrumEntropy - .5
+		sum += fit * fit
+	}
+	return sum / float64(len(data)), nil
+}
+
+// Mutate mutates the genome
+func (g *Genome) Mutate(rng *rand.Rand) {
+	eaopt.MutPermute(g.Connections, 1, rng)
+	eaopt.MutPermute(g.Weights, 1, rng)
+	eaopt.MutPermute(g.Types, 1, rng)
+	eaopt.MutPermute(g.Parameters, 1, rng)
+	eaopt.MutPermute(g.Bias, 1, rng)
+}
+
+// Crossover mates two genomes
+func (g *Genome) Crossover(r eaopt.Genome, rng *rand.Rand) {
+	eaopt.CrossGNX(g.Connections, r.(*Genome).Connections, 1, rng)
+	eaopt.CrossGNX(g.Weights, r.(*Genome).Weights, 1, rng)
+	eaopt.CrossGNX(g.Types, r.(*Genome).Types, 1, rng)
+	eaopt.CrossGNX(g.Parameters, r.(*Genome).Parameters, 1, rng)
+	eaopt.CrossGNX(g.Bias, r.(*Genome).Bias, 1, rng)
+}
+
+// Clone produces a copy of a genome
+func (g *Genome) Clone() eaopt.Genome {
+	connections := make(slices.Uint8, len(g.Connections))
+	weights := make(slices.Fixed, len(g.Weights))
+	types := make(slices.Uint8, len(g.Types))
+	parameters := make(slices.Fixed, len(g.Parameters))
+	bias := make(slices.Fixed, len(g.Bias))
+	copy(connections, g.Connections)
+	copy(weights, g.Weights)
+	copy(types, g.Types)
+	copy(parameters, g.Parameters)
+	copy(bias, g.Bias)
+	return &Genome{
+		Connections: connections,
+		Weights:     weights,
+		Types:       types,
+		Parameters:  parameters,
+		Bias:        bias,
+	}
+}
+
+// Write writes the genome to a file
+func (g *Genome) Write(name string) {
+	out, err := os.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	encoder := gob.NewEncoder(out)
+	err = encoder.Encode(g)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReadGenome reads a genome from a file
+func ReadGenome(name string) *Genome {
+	genome := Genome{}
+	in, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer in.Close()
+	decoder := gob.NewDecoder(in)
+	err = decoder.Decode(&genome)
+	if err != nil {
+		panic(err)
+	}
+	return &genome
+}
+
+// GenomeFactory creates a new genome
+func GenomeFactory(rnd *rand.Rand) eaopt.Genome {
+	connections := make(slices.Uint8, NetworkSize*NetworkSize)
+	for i := range connections {
+		if rnd.Intn(2) == 0 {
+			connections[i] = 255
+		} else {
+			connections[i] = uint8(rnd.Intn(255))
+		}
+	}
+	weights := make(slices.Fixed, NetworkSize*NetworkSize)
+	for i := range weights {
+		weights[i] = fixed.Fixed(rnd.Intn(MaxWeight * fixed.FixedOne))
+		if rnd.Intn(2) == 0 {
+			weights[i] = -weights[i]
+		}
+	}
+	types := make(slices.Uint8, NetworkSize)
+	for i := range types {
+		types[i] = uint8(rnd.Intn(NeuronTypes))
+	}
+	parameters := make(slices.Fixed, Parameters*NetworkSize)
+	for i := range parameters {
+		parameters[i] = fixed.Fixed(rnd.Intn(fixed.FixedOne))
+	}
+	bias := make(slices.Fixed, NetworkSize)
+	for i := range bias {
+		bias[i] = fixed.Fixed(rnd.Intn(MaxBias * fixed.FixedOne))
+	}
+	return &Genome{
+		Connections: connections,
+		Weights:     weights,
+		Types:       types,
+		Parameters:  parameters,
+		Bias:        bias,
+	}
+}
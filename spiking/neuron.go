@@ -0,0 +1,145 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spiking
+
+import (
+	"github.com/pointlander/sync/fixed"
+)
+
+const (
+	// NeuronLIF is a leaky integrate and fire neuron
+	NeuronLIF = iota
+	// NeuronIzhikevich is an Izhikevich neuron
+	NeuronIzhikevich
+	// NeuronTypes is the number of neuron types
+	NeuronTypes
+)
+
+const (
+	// Dt is the integration time step in milliseconds
+	Dt fixed.Fixed = fixed.FixedHalf
+	// Parameters is the number of parameters of a neuron, each in [0, 1)
+	Parameters = 4
+	// Rest is the resting potential in millivolts
+	Rest = -65 * fixed.FixedOne
+	// Peak is the spike cutoff of the Izhikevich neuron in millivolts
+	Peak = 30 * fixed.FixedOne
+	// Floor bounds the membrane potential from below so the fixed point square stays in range
+	Floor = -100 * fixed.FixedOne
+)
+
+// Neuron is a spiking neuron driven by an input current in millivolts per millisecond
+type Neuron interface {
+	// Step integrates the input for one time step and returns true if the neuron spiked
+	Step(input fixed.Fixed) bool
+	// Potential is the membrane potential in millivolts
+	Potential() fixed.Fixed
+	// Clone copies the neuron including its state
+	Clone() Neuron
+}
+
+// scale maps a parameter in [0, 1) to [low, low+width)
+func scale(parameter fixed.Fixed, low, width float64) fixed.Fixed {
+	return fixed.FixedFloat64(low) + fixed.FixedFloat64(width).Mul(parameter)
+}
+
+// NewNeuron creates a neuron of a neuron type from parameters in [0, 1)
+func NewNeuron(kind uint8, parameters [Parameters]fixed.Fixed) Neuron {
+	switch kind {
+	case NeuronLIF:
+		return &LIF{
+			V:          Rest,
+			Tau:        scale(parameters[0], 5, 25),
+			Threshold:  scale(parameters[1], -55, 10),
+			Reset:      scale(parameters[2], -75, 10),
+			Refractory: 2 + int(scale(parameters[3], 0, 8)>>fixed.Places),
+		}
+	case NeuronIzhikevich:
+		izhikevich := &Izhikevich{
+			V: Rest,
+			A: scale(parameters[0], .02, .08),
+			B: scale(parameters[1], .2, .05),
+			C: scale(parameters[2], -65, 15),
+			D: scale(parameters[3], 2, 6),
+		}
+		izhikevich.U = izhikevich.B.Mul(izhikevich.V)
+		return izhikevich
+	}
+	panic("unknown neuron type")
+}
+
+// LIF is a leaky integrate and fire neuron v' = (rest - v) / tau + input
+type LIF struct {
+	V, Tau, Threshold, Reset fixed.Fixed
+	// Refractory is the refractory period in steps and Remaining is what is left of it
+	Refractory, Remaining int
+}
+
+// Step integrates the input for one time step and returns true if the neuron spiked
+func (l *LIF) Step(input fixed.Fixed) bool {
+	if l.Remaining > 0 {
+		l.Remaining--
+		return false
+	}
+	leak := fixed.Fixed((int64(Rest-l.V) << fixed.Places) / int64(l.Tau))
+	l.V += Dt.Mul(leak + input)
+	if l.V < Floor {
+		l.V = Floor
+	}
+	if l.V >= l.Threshold {
+		l.V, l.Remaining = l.Reset, l.Refractory
+		return true
+	}
+	return false
+}
+
+// Potential is the membrane potential in millivolts
+func (l *LIF) Potential() fixed.Fixed {
+	return l.V
+}
+
+// Clone copies the neuron including its state
+func (l *LIF) Clone() Neuron {
+	clone := *l
+	return &clone
+}
+
+// Izhikevich is an Izhikevich neuron v' = .04 v^2 + 5 v + 140 - u + input and u' = a (b v - u),
+// when v reaches Peak v is reset to c and d is added to u
+type Izhikevich struct {
+	V, U       fixed.Fixed
+	A, B, C, D fixed.Fixed
+}
+
+var (
+	izhikevichSquare = fixed.FixedFloat64(.04)
+	izhikevichOffset = fixed.FixedFloat64(140)
+)
+
+// Step integrates the input for one time step and returns true if the neuron spiked
+func (z *Izhikevich) Step(input fixed.Fixed) bool {
+	v, u := z.V, z.U
+	z.V += Dt.Mul(izhikevichSquare.Mul(v.Mul(v)) + 5*v + izhikevichOffset - u + input)
+	z.U += Dt.Mul(z.A.Mul(z.B.Mul(v) - u))
+	if z.V < Floor {
+		z.V = Floor
+	}
+	if z.V >= Peak {
+		z.V, z.U = z.C, z.U+z.D
+		return true
+	}
+	return false
+}
+
+// Potential is the membrane potential in millivolts
+func (z *Izhikevich) Potential() fixed.Fixed {
+	return z.V
+}
+
+// Clone copies the neuron including its state
+func (z *Izhikevich) Clone() Neuron {
+	clone := *z
+	return &clone
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spiking
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pointlander/sync/fixed"
+)
+
+func TestLIF(t *testing.T) {
+	lif := &LIF{
+		V:          Rest,
+		Tau:        20 * fixed.FixedOne,
+		Threshold:  -50 * fixed.FixedOne,
+		Reset:      Rest,
+		Refractory: 4,
+	}
+	if rate := Rate(lif.Clone(), fixed.FixedHalf, Iterations); rate != 0 {
+		t.Fatalf("subthreshold input fired at %fHz", rate)
+	}
+	input := 2.0
+	period := 20*math.Log(20*input/(20*input-15)) + 4*Dt.Float64()
+	expected := 1000 / period
+	if rate := Rate(lif, fixed.FixedFloat64(input), 100000); math.Abs(rate-expected)/expected > .05 {
+		t.Fatalf("rate %fHz expected %fHz", rate, expected)
+	}
+}
+
+func TestIzhikevich(t *testing.T) {
+	parameters := [Parameters]fixed.Fixed{}
+	if rate := Rate(NewNeuron(NeuronIzhikevich, parameters), 0, Iterations); rate != 0 {
+		t.Fatalf("resting neuron fired at %fHz", rate)
+	}
+	low := Rate(NewNeuron(NeuronIzhikevich, parameters), 5*fixed.FixedOne, Iterations)
+	high := Rate(NewNeuron(NeuronIzhikevich, parameters), 15*fixed.FixedOne, Iterations)
+	if low <= 0 || high <= low {
+		t.Fatalf("rates %fHz and %fHz should increase with input", low, high)
+	}
+}
+
+func TestSynapse(t *testing.T) {
+	synapse := NewSynapse(0, 3, fixed.FixedOne)
+	synapse.Send()
+	for i := 1; i <= 4; i++ {
+		current := synapse.Step()
+		if (i == 3) != (current == fixed.FixedOne) {
+			t.Fatalf("step %d current %d", i, current)
+		}
+	}
+}
+
+func TestNetwork_Step(t *testing.T) {
+	genome := GenomeFactory(rand.New(rand.NewSource(1))).(*Genome)
+	a, b := genome.NewNetwork(), genome.NewNetwork()
+	spikes := 0
+	for i := 0; i < Iterations; i++ {
+		a.Step(nil)
+		b.Step(nil)
+		for j := range a.Neurons {
+			if a.Neurons[j].Potential() != b.Neurons[j].Potential() {
+				t.Fatalf("neuron %d differs at step %d", j, i)
+			}
+			if a.Spikes[j] {
+				spikes++
+			}
+		}
+	}
+	if spikes == 0 {
+		t.Fatal("network should spike")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 The Sync Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"math/rand/v2"
+)
+
+// Source is a random source whose internal state can be saved and restored
+type Source struct {
+	source *rand.PCG
+}
+
+// NewSource creates a new random source
+func NewSource(seed int64) *Source {
+	return &Source{
+		source: rand.NewPCG(uint64(seed), 0),
+	}
+}
+
+// Int63 returns a non-negative random 63 bit integer
+func (s *Source) Int63() int64 {
+	return int64(s.source.Uint64() &^ (1 << 63))
+}
+
+// Uint64 returns a random 64 bit integer
+func (s *Source) Uint64() uint64 {
+	return s.source.Uint64()
+}
+
+// Seed reseeds the source
+func (s *Source) Seed(seed int64) {
+	s.source.Seed(uint64(seed), 0)
+}
+
+// State returns the internal state of the source
+func (s *Source) State() []byte {
+	state, err := s.source.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+// Restore restores the internal state of the source
+func (s *Source) Restore(state []byte) {
+	err := s.source.UnmarshalBinary(state)
+	if err != nil {
+		panic(err)
+	}
+}
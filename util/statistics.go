@@ -60,3 +60,21 @@ func (m *Markov) Entropy() float64 {
 	}
 	return -entropy
 }
+
+// MeanVariance computes the mean and variance of a set of values
+func MeanVariance(values []float64) (mean, variance float64) {
+	n := float64(len(values))
+	if n == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+	for _, v := range values {
+		d := v - mean
+		variance += d * d
+	}
+	variance /= n
+	return mean, variance
+}